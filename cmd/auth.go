@@ -2,20 +2,39 @@ package cmd
 
 import (
 	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/goravaa/apache-polaris-cli/pkg/api"
 	"github.com/goravaa/apache-polaris-cli/pkg/config"
+	"github.com/goravaa/apache-polaris-cli/pkg/output"
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
 )
 
+const oidcLoginTimeout = 5 * time.Minute
+
 var (
 	clientID     string
 	clientSecret string
+	loginScope   string
+	assumeClear  bool
+	statusJWKS   string
+	statusHMAC   bool
+	loginOIDC    bool
+	loginDevice  bool
+	loginNoOpen  bool
+	tokenFormat  string
 )
 
 var authCmd = &cobra.Command{
@@ -36,7 +55,22 @@ Or you can run interactively (will prompt for credentials):
   polaris auth login
 
 Environment variables are also supported:
-  POLARIS_CLIENT_ID and POLARIS_CLIENT_SECRET`,
+  POLARIS_CLIENT_ID and POLARIS_CLIENT_SECRET
+
+If the profile has a credential_process, its output is used instead and the
+client secret is never written to disk:
+  polaris config set --credential-process "vault kv get -format=json -field=data secret/polaris"
+
+By default the token carries all of the principal's roles. Use --scope to
+request a narrower token:
+  polaris auth login --scope PRINCIPAL_ROLE:data_engineer
+
+If the Polaris server trusts tokens from an external identity provider,
+configure it on the profile and log in through the browser or, on headless
+machines, with a device code:
+  polaris config set --oidc-issuer https://idp.example.com --oidc-client-id polaris-cli
+  polaris auth login --oidc
+  polaris auth login --oidc --device`,
 	RunE: runLogin,
 }
 
@@ -50,15 +84,57 @@ var logoutCmd = &cobra.Command{
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show authentication status",
-	Long:  `Check if you are currently authenticated with the Polaris server.`,
-	RunE:  runStatus,
+	Long: `Check if you are currently authenticated with the Polaris server.
+
+The access token's JWT claims are decoded and shown without verification.
+Pass --jwks with a JSON Web Key Set file to also verify the signature.
+Exits with a non-zero status if the token has expired or fails verification.`,
+	RunE: runStatus,
 }
 
 var refreshCmd = &cobra.Command{
 	Use:   "refresh",
 	Short: "Refresh the access token",
-	Long:  `Refresh the current access token using the stored credentials.`,
-	RunE:  runRefresh,
+	Long: `Refresh the current access token the same way it was obtained: with the
+stored client credentials, the identity provider's refresh token or the
+profile's credential_process. An assumed role's token is refreshed too.`,
+	RunE: runRefresh,
+}
+
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Print a valid access token",
+	Long: `Print an access token for use with other tools, renewing it first if it
+has expired or is about to.
+
+Formats:
+  raw     the bare token (default)
+  header  an HTTP Authorization header
+  json    {"access_token": ..., "token_type": ..., "expires_at": ...}
+  env     POLARIS_TOKEN=<token>, suitable for eval or env files
+
+--format applies to the default table output. With --output json or yaml
+the json form is printed, and --output name prints the bare token.
+
+Examples:
+  curl -H "$(polaris auth token --format header)" https://polaris.example.com/api/management/v1/catalogs
+  eval "export $(polaris auth token --format env)"`,
+	Args: cobra.NoArgs,
+	RunE: runToken,
+}
+
+var assumeRoleCmd = &cobra.Command{
+	Use:   "assume-role <principal-role>",
+	Short: "Use a token scoped to a single principal role",
+	Long: `Obtain a token that only carries the given principal role and use it for
+subsequent commands. The full-scope token is kept, and --clear switches back
+to it.
+
+Examples:
+  polaris auth assume-role data_reader
+  polaris auth assume-role --clear`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runAssumeRole,
 }
 
 func init() {
@@ -67,9 +143,66 @@ func init() {
 	authCmd.AddCommand(logoutCmd)
 	authCmd.AddCommand(statusCmd)
 	authCmd.AddCommand(refreshCmd)
+	authCmd.AddCommand(assumeRoleCmd)
+	authCmd.AddCommand(tokenCmd)
 
 	loginCmd.Flags().StringVar(&clientID, "client-id", "", "OAuth client ID")
 	loginCmd.Flags().StringVar(&clientSecret, "client-secret", "", "OAuth client secret")
+	loginCmd.Flags().StringVar(&loginScope, "scope", api.DefaultScope, "OAuth scope to request (e.g., PRINCIPAL_ROLE:<role>)")
+	loginCmd.Flags().BoolVar(&loginOIDC, "oidc", false, "Log in through the profile's OIDC identity provider")
+	loginCmd.Flags().BoolVar(&loginDevice, "device", false, "Use the OIDC device-code flow instead of a browser (with --oidc)")
+	loginCmd.Flags().BoolVar(&loginNoOpen, "no-browser", false, "Print the OIDC login URL instead of opening a browser (with --oidc)")
+
+	statusCmd.Flags().StringVar(&statusJWKS, "jwks", "", "JWKS file used to verify the token signature")
+	statusCmd.Flags().BoolVar(&statusHMAC, "jwks-allow-hmac", false, "Also verify HS256/384/512 signatures with symmetric (oct) keys from --jwks")
+
+	tokenCmd.Flags().StringVar(&tokenFormat, "format", "raw", "Output format: raw, header, json or env")
+
+	assumeRoleCmd.Flags().BoolVar(&assumeClear, "clear", false, "Drop the assumed role and use the full-scope token again")
+
+	setColumns(loginCmd, tokenHeaders)
+	setColumns(logoutCmd, actionHeaders)
+	setColumns(statusCmd, statusHeaders)
+	setColumns(refreshCmd, tokenHeaders)
+	setColumns(tokenCmd, accessTokenHeaders)
+	resultColumns[assumeRoleCmd] = func() []string {
+		if assumeClear {
+			return actionHeaders
+		}
+		return tokenHeaders
+	}
+
+	config.PassphraseFunc = promptPassphrase
+}
+
+func promptPassphrase(confirm bool) (string, error) {
+	if p := os.Getenv(config.EnvCredentialPassphrase); p != "" {
+		return p, nil
+	}
+	if !term.IsTerminal(int(syscall.Stdin)) {
+		return "", fmt.Errorf("a passphrase is required for the encrypted credential store. Set %s", config.EnvCredentialPassphrase)
+	}
+
+	fmt.Fprint(os.Stderr, "Credentials passphrase: ")
+	passphrase, err := term.ReadPassword(int(syscall.Stdin))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+
+	if confirm {
+		fmt.Fprint(os.Stderr, "Confirm passphrase: ")
+		again, err := term.ReadPassword(int(syscall.Stdin))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read passphrase: %w", err)
+		}
+		if string(again) != string(passphrase) {
+			return "", fmt.Errorf("passphrases do not match")
+		}
+	}
+
+	return string(passphrase), nil
 }
 
 func runLogin(cmd *cobra.Command, args []string) error {
@@ -79,7 +212,17 @@ func runLogin(cmd *cobra.Command, args []string) error {
 	}
 
 	if cfg.Host == "" {
-		return fmt.Errorf("Polaris host not configured. Run 'polaris config set --host <url>' first")
+		return usageErrorf("Polaris host not configured. Run 'polaris config set --host <url>' first")
+	}
+
+	if loginOIDC {
+		return runOIDCLogin(cmd.Context(), cfg)
+	}
+	if loginDevice || loginNoOpen {
+		return usageErrorf("--device and --no-browser require --oidc")
+	}
+	if cfg.CredentialProcess != "" {
+		return runCredentialProcessLogin(cmd.Context(), cfg)
 	}
 
 	id := clientID
@@ -87,7 +230,7 @@ func runLogin(cmd *cobra.Command, args []string) error {
 		id = os.Getenv("POLARIS_CLIENT_ID")
 	}
 	if id == "" {
-		fmt.Print("Client ID: ")
+		fmt.Fprint(os.Stderr, "Client ID: ")
 		reader := bufio.NewReader(os.Stdin)
 		id, err = reader.ReadString('\n')
 		if err != nil {
@@ -97,7 +240,7 @@ func runLogin(cmd *cobra.Command, args []string) error {
 	}
 
 	if id == "" {
-		return fmt.Errorf("client ID is required")
+		return usageErrorf("client ID is required")
 	}
 
 	secret := clientSecret
@@ -105,39 +248,170 @@ func runLogin(cmd *cobra.Command, args []string) error {
 		secret = os.Getenv("POLARIS_CLIENT_SECRET")
 	}
 	if secret == "" {
-		fmt.Print("Client Secret: ")
+		fmt.Fprint(os.Stderr, "Client Secret: ")
 		secretBytes, err := term.ReadPassword(int(syscall.Stdin))
 		if err != nil {
 			return fmt.Errorf("failed to read client secret: %w", err)
 		}
-		fmt.Println()
+		fmt.Fprintln(os.Stderr)
 		secret = string(secretBytes)
 	}
 
 	if secret == "" {
-		return fmt.Errorf("client secret is required")
+		return usageErrorf("client secret is required")
 	}
 
-	fmt.Printf("Authenticating with %s...\n", cfg.Host)
-	authClient := api.NewAuthClient(cfg)
-	credentials, err := authClient.Login(id, secret)
+	fmt.Fprintf(os.Stderr, "Authenticating with %s...\n", cfg.Host)
+	authClient, err := newAuthClient(cfg)
+	if err != nil {
+		return err
+	}
+	credentials, err := authClient.LoginWithScope(cmd.Context(), id, secret, loginScope)
 	if err != nil {
 		return fmt.Errorf("authentication failed: %w", err)
 	}
 
+	return saveLogin(cfg, credentials)
+}
+
+func runCredentialProcessLogin(ctx context.Context, cfg *config.Config) error {
+	if clientID != "" || clientSecret != "" {
+		return usageErrorf("--client-id and --client-secret cannot be used when profile %q has a credential_process", cfg.Name)
+	}
+
+	fmt.Fprintf(os.Stderr, "Authenticating with %s using credential_process...\n", cfg.Host)
+	authClient, err := newAuthClient(cfg)
+	if err != nil {
+		return err
+	}
+	credentials, err := authClient.LoginWithCredentialProcess(ctx, loginScope)
+	if err != nil {
+		return fmt.Errorf("authentication failed: %w", err)
+	}
+
+	return saveLogin(cfg, credentials)
+}
+
+// saveLogin stores the credentials from a client-credentials login and
+// reports the new token.
+func saveLogin(cfg *config.Config, credentials *config.Credentials) error {
+	if err := config.SaveCredentials(credentials); err != nil {
+		return fmt.Errorf("failed to save credentials: %w", err)
+	}
+
+	return printResult(tokenResult(cfg, credentials, func(w io.Writer) {
+		fmt.Fprintln(w, "✓ Successfully authenticated!")
+		if credentials.ExpiresIn > 0 {
+			fmt.Fprintf(w, "  Token expires in: %d seconds\n", credentials.ExpiresIn)
+		}
+		if credentials.Scope != "" {
+			fmt.Fprintf(w, "  Scope: %s\n", credentials.Scope)
+		}
+	}))
+}
+
+func runOIDCLogin(ctx context.Context, cfg *config.Config) error {
+	factory, err := newClientFactoryWithConfig(cfg, api.WithoutAuth())
+	if err != nil {
+		return err
+	}
+
+	oidc, err := factory.OIDC()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, oidcLoginTimeout)
+	defer cancel()
+
+	var credentials *config.Credentials
+	if loginDevice {
+		auth, err := oidc.StartDeviceLogin(ctx)
+		if err != nil {
+			return fmt.Errorf("authentication failed: %w", err)
+		}
+		if auth.VerificationURIComplete != "" {
+			fmt.Fprintf(os.Stderr, "To log in, open %s\n", auth.VerificationURIComplete)
+			fmt.Fprintf(os.Stderr, "and confirm the code %s\n", auth.UserCode)
+		} else {
+			fmt.Fprintf(os.Stderr, "To log in, open %s\n", auth.VerificationURI)
+			fmt.Fprintf(os.Stderr, "and enter the code %s\n", auth.UserCode)
+		}
+		fmt.Fprintln(os.Stderr, "Waiting for approval...")
+		credentials, err = oidc.PollDeviceLogin(ctx, auth)
+		if err != nil {
+			return fmt.Errorf("authentication failed: %w", err)
+		}
+	} else {
+		credentials, err = oidc.LoginBrowser(ctx, func(authURL string) error {
+			fmt.Fprintln(os.Stderr, "Complete the login in your browser. If it does not open, visit:")
+			fmt.Fprintf(os.Stderr, "  %s\n", authURL)
+			if !loginNoOpen {
+				_ = openBrowser(authURL)
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("authentication failed: %w", err)
+		}
+	}
+
 	if err := config.SaveCredentials(credentials); err != nil {
 		return fmt.Errorf("failed to save credentials: %w", err)
 	}
 
-	fmt.Println("✓ Successfully authenticated!")
-	if credentials.ExpiresIn > 0 {
-		fmt.Printf("  Token expires in: %d seconds\n", credentials.ExpiresIn)
+	return printResult(tokenResult(cfg, credentials, func(w io.Writer) {
+		fmt.Fprintln(w, "✓ Successfully authenticated!")
+		if credentials.ExpiresIn > 0 {
+			fmt.Fprintf(w, "  Token expires in: %d seconds\n", credentials.ExpiresIn)
+		}
+		if credentials.RefreshToken == "" {
+			fmt.Fprintln(w, "  Note: no refresh token was issued; run 'polaris auth login --oidc' again when the token expires")
+		}
+	}))
+}
+
+// tokenInfo describes a newly obtained token without revealing it.
+type tokenInfo struct {
+	Profile   string `json:"profile"`
+	Host      string `json:"host"`
+	TokenType string `json:"token_type,omitempty"`
+	Scope     string `json:"scope,omitempty"`
+	ExpiresIn int    `json:"expires_in,omitempty"`
+}
+
+var tokenHeaders = []string{"PROFILE", "HOST", "SCOPE", "EXPIRES IN"}
+
+func tokenResult(cfg *config.Config, creds *config.Credentials, text func(w io.Writer)) *output.Result {
+	info := tokenInfo{
+		Profile:   cfg.Name,
+		Host:      cfg.Host,
+		TokenType: creds.TokenType,
+		Scope:     creds.Scope,
+		ExpiresIn: creds.ExpiresIn,
 	}
-	if credentials.Scope != "" {
-		fmt.Printf("  Scope: %s\n", credentials.Scope)
+	return &output.Result{
+		Object:  info,
+		Headers: tokenHeaders,
+		Rows:    [][]string{{info.Profile, info.Host, info.Scope, strconv.Itoa(info.ExpiresIn)}},
+		Text: func(w io.Writer) error {
+			text(w)
+			return nil
+		},
 	}
+}
 
-	return nil
+func openBrowser(target string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", target)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", target)
+	default:
+		cmd = exec.Command("xdg-open", target)
+	}
+	return cmd.Start()
 }
 
 func runLogout(cmd *cobra.Command, args []string) error {
@@ -146,49 +420,333 @@ func runLogout(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	authClient := api.NewAuthClient(cfg)
+	authClient, err := newAuthClient(cfg)
+	if err != nil {
+		return err
+	}
 	if err := authClient.Logout(); err != nil {
 		return fmt.Errorf("failed to log out: %w", err)
 	}
 
-	fmt.Println("✓ Successfully logged out!")
-	return nil
+	return printResult(actionResult("profile", cfg.Name, "logged out", "✓ Successfully logged out!"))
+}
+
+type authStatus struct {
+	Profile           string           `json:"profile"`
+	Host              string           `json:"host"`
+	Realm             string           `json:"realm,omitempty"`
+	Authenticated     bool             `json:"authenticated"`
+	TokenSource       string           `json:"token_source,omitempty"`
+	TokenType         string           `json:"token_type,omitempty"`
+	ClientID          string           `json:"client_id,omitempty"`
+	Scope             string           `json:"scope,omitempty"`
+	AssumedScope      string           `json:"assumed_scope,omitempty"`
+	ActiveRoles       string           `json:"active_roles,omitempty"`
+	ExpiresAt         *time.Time       `json:"expires_at,omitempty"`
+	Expired           bool             `json:"expired"`
+	Claims            *api.TokenClaims `json:"claims,omitempty"`
+	SignatureVerified *bool            `json:"signature_verified,omitempty"`
+	SignatureError    string           `json:"signature_error,omitempty"`
+
+	token string
 }
 
+var statusHeaders = []string{"PROFILE", "HOST", "AUTHENTICATED", "EXPIRED", "EXPIRES AT", "ACTIVE ROLES"}
+
 func runStatus(cmd *cobra.Command, args []string) error {
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	fmt.Printf("Host: %s\n", cfg.Host)
-	if cfg.Realm != "" {
-		fmt.Printf("Realm: %s\n", cfg.Realm)
+	status := &authStatus{
+		Profile: cfg.Name,
+		Host:    cfg.Host,
+		Realm:   cfg.Realm,
+	}
+
+	// Only missing credentials are a status to report. Anything else, such
+	// as a wrong passphrase for the encrypted store, is an error.
+	token, source, err := config.ResolveToken()
+	if err != nil && !errors.Is(err, config.ErrNotAuthenticated) {
+		return err
+	}
+	if err == nil {
+		status.Authenticated = true
+		status.TokenSource = source
+		status.token = token
+
+		if source == config.TokenSourceCredentials {
+			creds, err := config.LoadCredentials()
+			if err != nil {
+				return err
+			}
+			status.TokenType = creds.TokenType
+			status.ClientID = creds.ClientID
+			status.Scope = creds.Scope
+			active := creds.Active()
+			if active != creds {
+				status.AssumedScope = active.Scope
+			}
+			if expiresAt := active.ExpiresAt(); !expiresAt.IsZero() {
+				status.ExpiresAt = &expiresAt
+			}
+		}
+
+		if claims, err := api.DecodeJWT(token); err == nil {
+			status.Claims = claims
+			if claims.ExpiresAt != nil {
+				status.ExpiresAt = claims.ExpiresAt
+			}
+		}
+
+		if statusJWKS != "" {
+			jwks, err := os.ReadFile(statusJWKS)
+			if err != nil {
+				return fmt.Errorf("failed to read JWKS file: %w", err)
+			}
+			verified := true
+			if err := api.VerifyJWT(token, jwks, statusHMAC); err != nil {
+				verified = false
+				status.SignatureError = err.Error()
+			}
+			status.SignatureVerified = &verified
+		}
+
+		activeScope := status.Scope
+		if status.AssumedScope != "" {
+			activeScope = status.AssumedScope
+		}
+		if status.Claims != nil && len(status.Claims.Scopes) > 0 {
+			activeScope = strings.Join(status.Claims.Scopes, " ")
+		}
+		status.ActiveRoles = describeActiveRoles(activeScope)
+		status.Expired = status.ExpiresAt != nil && time.Now().After(*status.ExpiresAt)
+	}
+
+	expiresAt := ""
+	if status.ExpiresAt != nil {
+		expiresAt = status.ExpiresAt.Format(time.RFC3339)
+	}
+	result := &output.Result{
+		Object:  status,
+		Headers: statusHeaders,
+		Rows: [][]string{{status.Profile, status.Host, strconv.FormatBool(status.Authenticated),
+			strconv.FormatBool(status.Expired), expiresAt, status.ActiveRoles}},
+		Text: func(w io.Writer) error {
+			writeAuthStatus(w, status)
+			return nil
+		},
+	}
+	if err := printResult(result); err != nil {
+		return err
+	}
+
+	if status.Expired {
+		return withExitCode(ExitAuth, fmt.Errorf("access token expired at %s. Run 'polaris auth login' or 'polaris auth refresh'", status.ExpiresAt.Local().Format(time.RFC3339)))
+	}
+	if status.SignatureVerified != nil && !*status.SignatureVerified {
+		return withExitCode(ExitAuth, fmt.Errorf("token signature could not be verified: %s", status.SignatureError))
+	}
+
+	return nil
+}
+
+func writeAuthStatus(w io.Writer, status *authStatus) {
+	fmt.Fprintf(w, "Host: %s\n", status.Host)
+	if status.Realm != "" {
+		fmt.Fprintf(w, "Realm: %s\n", status.Realm)
+	}
+
+	if !status.Authenticated {
+		fmt.Fprintln(w, "Status: Not authenticated")
+		fmt.Fprintln(w, "\nRun 'polaris auth login' to authenticate.")
+		return
+	}
+
+	if status.Expired {
+		fmt.Fprintln(w, "Status: Token expired ✗")
+	} else {
+		fmt.Fprintln(w, "Status: Authenticated ✓")
+	}
+	if status.TokenSource != config.TokenSourceCredentials {
+		fmt.Fprintf(w, "Token Source: %s\n", status.TokenSource)
+	}
+	if status.TokenType != "" {
+		fmt.Fprintf(w, "Token Type: %s\n", status.TokenType)
+	}
+	if status.Scope != "" {
+		fmt.Fprintf(w, "Scope: %s\n", status.Scope)
+	}
+	if status.ClientID != "" {
+		fmt.Fprintf(w, "Client ID: %s\n", status.ClientID)
+	}
+	if status.AssumedScope != "" {
+		fmt.Fprintf(w, "Assumed Scope: %s\n", status.AssumedScope)
+	}
+	fmt.Fprintf(w, "Active Roles: %s\n", status.ActiveRoles)
+
+	if c := status.Claims; c != nil {
+		fmt.Fprintln(w, "Token Claims:")
+		if c.Subject != "" {
+			fmt.Fprintf(w, "  Subject: %s\n", c.Subject)
+		}
+		if c.PrincipalID != "" {
+			fmt.Fprintf(w, "  Principal ID: %s\n", c.PrincipalID)
+		}
+		if c.ClientID != "" {
+			fmt.Fprintf(w, "  Client ID: %s\n", c.ClientID)
+		}
+		if len(c.Scopes) > 0 {
+			fmt.Fprintf(w, "  Scopes: %s\n", strings.Join(c.Scopes, " "))
+		}
+		if c.Issuer != "" {
+			fmt.Fprintf(w, "  Issuer: %s\n", c.Issuer)
+		}
+		if c.IssuedAt != nil {
+			fmt.Fprintf(w, "  Issued At: %s\n", c.IssuedAt.Local().Format(time.RFC3339))
+		}
+	}
+
+	if status.ExpiresAt != nil {
+		remaining := time.Until(*status.ExpiresAt).Round(time.Second)
+		if remaining > 0 {
+			fmt.Fprintf(w, "Expires At: %s (in %s)\n", status.ExpiresAt.Local().Format(time.RFC3339), remaining)
+		} else {
+			fmt.Fprintf(w, "Expires At: %s (%s ago)\n", status.ExpiresAt.Local().Format(time.RFC3339), -remaining)
+		}
+	}
+
+	if status.SignatureVerified != nil {
+		if *status.SignatureVerified {
+			fmt.Fprintln(w, "Signature: verified ✓")
+		} else {
+			fmt.Fprintln(w, "Signature: NOT verified ✗")
+		}
+	}
+
+	if len(status.token) > 20 {
+		fmt.Fprintf(w, "Access Token: %s...%s\n", status.token[:10], status.token[len(status.token)-5:])
+	}
+}
+
+var accessTokenHeaders = []string{"ACCESS TOKEN", "TOKEN TYPE", "EXPIRES AT"}
+
+func runToken(cmd *cobra.Command, args []string) error {
+	switch tokenFormat {
+	case "raw", "header", "json", "env":
+	default:
+		return usageErrorf("invalid format %q (expected raw, header, json or env)", tokenFormat)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	factory, err := newClientFactoryWithConfig(cfg)
+	if err != nil {
+		return err
+	}
+	tokens := factory.Tokens()
+
+	token, err := tokens.Token(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	out := struct {
+		AccessToken string     `json:"access_token"`
+		TokenType   string     `json:"token_type"`
+		ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	}{AccessToken: token, TokenType: "Bearer"}
+	expiresAt := ""
+	if t := tokens.ExpiresAt(); !t.IsZero() {
+		out.ExpiresAt = &t
+		expiresAt = t.Format(time.RFC3339)
+	}
+
+	result := &output.Result{
+		Object:  out,
+		Headers: accessTokenHeaders,
+		Rows:    [][]string{{token, out.TokenType, expiresAt}},
+		Names:   []string{token},
+		Text: func(w io.Writer) error {
+			var err error
+			switch tokenFormat {
+			case "raw":
+				_, err = fmt.Fprintln(w, token)
+			case "header":
+				_, err = fmt.Fprintf(w, "Authorization: Bearer %s\n", token)
+			case "env":
+				_, err = fmt.Fprintf(w, "%s=%s\n", config.EnvToken, token)
+			case "json":
+				enc := json.NewEncoder(w)
+				enc.SetIndent("", "  ")
+				err = enc.Encode(out)
+			}
+			return err
+		},
+	}
+	return printResult(result)
+}
+
+func describeActiveRoles(scope string) string {
+	roles, all := api.ActiveRoles(scope)
+	switch {
+	case all:
+		return "all principal roles"
+	case len(roles) == 0:
+		return "(unknown)"
+	default:
+		return strings.Join(roles, ", ")
+	}
+}
+
+func runAssumeRole(cmd *cobra.Command, args []string) error {
+	if assumeClear == (len(args) == 1) {
+		return usageErrorf("specify either a principal role or --clear")
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
 	}
 
 	creds, err := config.LoadCredentials()
 	if err != nil {
-		fmt.Println("Status: Not authenticated")
-		fmt.Println("\nRun 'polaris auth login' to authenticate.")
-		return nil
+		return fmt.Errorf("not authenticated: %w", err)
 	}
 
-	fmt.Println("Status: Authenticated ✓")
-	if creds.TokenType != "" {
-		fmt.Printf("Token Type: %s\n", creds.TokenType)
+	if assumeClear {
+		creds.Scoped = nil
+		if err := config.SaveCredentials(creds); err != nil {
+			return fmt.Errorf("failed to save credentials: %w", err)
+		}
+		return printResult(actionResult("profile", cfg.Name, "assumed role dropped", "✓ Dropped assumed role; using the full-scope token"))
 	}
-	if creds.Scope != "" {
-		fmt.Printf("Scope: %s\n", creds.Scope)
+
+	role := args[0]
+	fmt.Fprintf(os.Stderr, "Assuming principal role %s...\n", role)
+	authClient, err := newAuthClient(cfg)
+	if err != nil {
+		return err
 	}
-	if creds.ClientID != "" {
-		fmt.Printf("Client ID: %s\n", creds.ClientID)
+	assumed, err := authClient.AssumeRole(cmd.Context(), creds, role)
+	if err != nil {
+		return fmt.Errorf("failed to assume role: %w", err)
 	}
 
-	if len(creds.AccessToken) > 20 {
-		fmt.Printf("Access Token: %s...%s\n", creds.AccessToken[:10], creds.AccessToken[len(creds.AccessToken)-5:])
+	if err := config.SaveCredentials(assumed); err != nil {
+		return fmt.Errorf("failed to save credentials: %w", err)
 	}
 
-	return nil
+	return printResult(tokenResult(cfg, assumed.Scoped, func(w io.Writer) {
+		fmt.Fprintf(w, "✓ Now using principal role %s\n", role)
+		if assumed.Scoped.ExpiresIn > 0 {
+			fmt.Fprintf(w, "  Token expires in: %d seconds\n", assumed.Scoped.ExpiresIn)
+		}
+	}))
 }
 
 func runRefresh(cmd *cobra.Command, args []string) error {
@@ -202,9 +760,12 @@ func runRefresh(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("not authenticated: %w", err)
 	}
 
-	fmt.Println("Refreshing access token...")
-	authClient := api.NewAuthClient(cfg)
-	newCreds, err := authClient.RefreshToken(creds.AccessToken)
+	fmt.Fprintln(os.Stderr, "Refreshing access token...")
+	authClient, err := newAuthClient(cfg)
+	if err != nil {
+		return err
+	}
+	newCreds, err := authClient.Renew(cmd.Context(), creds)
 	if err != nil {
 		return fmt.Errorf("failed to refresh token: %w", err)
 	}
@@ -213,10 +774,10 @@ func runRefresh(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to save credentials: %w", err)
 	}
 
-	fmt.Println("✓ Token refreshed successfully!")
-	if newCreds.ExpiresIn > 0 {
-		fmt.Printf("  New token expires in: %d seconds\n", newCreds.ExpiresIn)
-	}
-
-	return nil
+	return printResult(tokenResult(cfg, newCreds, func(w io.Writer) {
+		fmt.Fprintln(w, "✓ Token refreshed successfully!")
+		if newCreds.ExpiresIn > 0 {
+			fmt.Fprintf(w, "  New token expires in: %d seconds\n", newCreds.ExpiresIn)
+		}
+	}))
 }
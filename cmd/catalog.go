@@ -2,18 +2,30 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/goravaa/apache-polaris-cli/pkg/api"
 	catalogapi "github.com/goravaa/apache-polaris-cli/pkg/api/openapi/catalog"
 	"github.com/goravaa/apache-polaris-cli/pkg/config"
+	"github.com/goravaa/apache-polaris-cli/pkg/output"
 	"github.com/spf13/cobra"
 )
 
-var catalogPrefix string
+var (
+	catalogPrefix    string
+	catalogWarehouse string
+	catalogNoCache   bool
+	catalogRefresh   bool
+)
 
 var catalogCmd = &cobra.Command{
 	Use:   "catalog",
@@ -23,6 +35,9 @@ var catalogCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(catalogCmd)
 	catalogCmd.PersistentFlags().StringVar(&catalogPrefix, "prefix", "", "Catalog prefix (required if not set in config)")
+	catalogCmd.PersistentFlags().StringVar(&catalogWarehouse, "warehouse", "", "Warehouse to request from the server's catalog config; its prefix is used unless --prefix is given")
+	catalogCmd.PersistentFlags().BoolVar(&catalogNoCache, "no-cache", false, "Neither read nor update the local table and view metadata cache")
+	catalogCmd.PersistentFlags().BoolVar(&catalogRefresh, "refresh", false, "Reload table and view metadata from the server and update the cache")
 }
 
 func newCatalogClient() (*catalogapi.ClientWithResponses, *config.Config, error) {
@@ -31,46 +46,116 @@ func newCatalogClient() (*catalogapi.ClientWithResponses, *config.Config, error)
 		return nil, nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	token, err := config.GetAccessToken()
+	var opts []api.FactoryOption
+	if !catalogNoCache {
+		dir, err := config.CacheDir(cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		opts = append(opts, api.WithMetadataCache(api.NewMetadataCache(dir, catalogRefresh)))
+	}
+
+	factory, err := newClientFactoryWithConfig(cfg, opts...)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	baseURL := strings.TrimSuffix(cfg.Host, "/") + "/api/catalog"
-	httpClient := &http.Client{Timeout: 30 * time.Second}
-
-	client, err := catalogapi.NewClientWithResponses(
-		baseURL,
-		catalogapi.WithHTTPClient(httpClient),
-		catalogapi.WithRequestEditorFn(func(ctx context.Context, req *http.Request) error {
-			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-			if cfg.Realm != "" {
-				req.Header.Set(api.RealmHeaderName, cfg.Realm)
-			}
-			return nil
-		}),
-	)
+	client, err := factory.Catalog()
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create catalog client: %w", err)
+		return nil, nil, err
 	}
 
-	return client, cfg, nil
+	return client, factory.Config(), nil
 }
 
 func resolveCatalogPrefix(cfg *config.Config) (string, error) {
-	if catalogPrefix != "" {
-		return catalogPrefix, nil
-	}
 	if cfg.CatalogPrefix != "" {
 		return cfg.CatalogPrefix, nil
 	}
-	return "", fmt.Errorf("catalog prefix is required. Use --prefix or set --catalog-prefix in config")
+	return "", usageErrorf("catalog prefix is required. Use --prefix or --warehouse, POLARIS_CATALOG_PREFIX or set --catalog-prefix in config")
+}
+
+// catalogTarget returns the prefix to use for a call to endpoint, failing
+// fast if the server's catalog config does not list endpoint. With a
+// warehouse, the config is required and its overrides.prefix takes
+// precedence over a prefix from the profile. Without one, the config is
+// requested for the configured prefix, which Polaris also uses as the
+// warehouse name; servers that reject it as an unknown warehouse, or that
+// advertise no endpoints, are not checked.
+func catalogTarget(ctx context.Context, client *catalogapi.ClientWithResponses, cfg *config.Config, endpoint string) (string, error) {
+	if cfg.Warehouse == "" {
+		prefix, err := resolveCatalogPrefix(cfg)
+		if err != nil {
+			return "", err
+		}
+		catalogConfig, err := getCatalogConfig(ctx, client, prefix)
+		if err != nil {
+			var apiErr *api.APIError
+			if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusBadRequest || apiErr.StatusCode == http.StatusNotFound) {
+				return prefix, nil
+			}
+			return "", err
+		}
+		if err := requireAdvertised(catalogConfig, endpoint); err != nil {
+			return "", err
+		}
+		return prefix, nil
+	}
+
+	catalogConfig, err := getCatalogConfig(ctx, client, cfg.Warehouse)
+	if err != nil {
+		return "", err
+	}
+	if err := api.NewCapabilities(catalogConfig.Endpoints).Require(endpoint); err != nil {
+		return "", err
+	}
+
+	source := cfg.Sources[config.SettingCatalogPrefix]
+	explicit := strings.HasPrefix(source, "flag ") || strings.HasPrefix(source, "env ")
+	if prefix := catalogConfig.Overrides["prefix"]; prefix != "" && !explicit {
+		return prefix, nil
+	}
+	return resolveCatalogPrefix(cfg)
+}
+
+// requireAdvertised checks endpoint against the endpoints the server lists.
+// The spec defaults are not assumed here, since the config was not asked
+// for explicitly.
+func requireAdvertised(catalogConfig *catalogapi.CatalogConfig, endpoint string) error {
+	capabilities := api.NewCapabilities(catalogConfig.Endpoints)
+	if !capabilities.Advertised() {
+		return nil
+	}
+	return capabilities.Require(endpoint)
+}
+
+// catalogConfigs holds the catalog configs fetched by this invocation, by
+// warehouse, so that the server is asked at most once.
+var catalogConfigs = map[string]*catalogapi.CatalogConfig{}
+
+func getCatalogConfig(ctx context.Context, client *catalogapi.ClientWithResponses, warehouse string) (*catalogapi.CatalogConfig, error) {
+	if catalogConfig, ok := catalogConfigs[warehouse]; ok {
+		return catalogConfig, nil
+	}
+	params := &catalogapi.GetConfigParams{}
+	if warehouse != "" {
+		params.Warehouse = &warehouse
+	}
+	resp, err := client.GetConfigWithResponse(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if resp.JSON200 == nil {
+		return nil, fmt.Errorf("failed to get catalog config: %w", api.ResponseError(resp.HTTPResponse, resp.Body))
+	}
+	catalogConfigs[warehouse] = resp.JSON200
+	return resp.JSON200, nil
 }
 
 func parseNamespaceArg(input string) ([]string, error) {
 	trimmed := strings.TrimSpace(input)
 	if trimmed == "" {
-		return nil, fmt.Errorf("namespace is required")
+		return nil, usageErrorf("namespace is required")
 	}
 	if strings.Contains(trimmed, "\x1f") {
 		return strings.Split(trimmed, "\x1f"), nil
@@ -101,9 +186,102 @@ func parseProperties(pairs []string) (map[string]string, error) {
 	for _, pair := range pairs {
 		kv := strings.SplitN(pair, "=", 2)
 		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
-			return nil, fmt.Errorf("invalid property %q (expected key=value)", pair)
+			return nil, usageErrorf("invalid property %q (expected key=value)", pair)
 		}
 		props[strings.TrimSpace(kv[0])] = kv[1]
 	}
 	return props, nil
 }
+
+func formatType(t catalogapi.Type) string {
+	if primitive, err := t.AsPrimitiveType(); err == nil {
+		return primitive
+	}
+	data, err := t.MarshalJSON()
+	if err != nil {
+		return "?"
+	}
+	var nested struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &nested); err != nil || nested.Type == "" {
+		return string(data)
+	}
+	return nested.Type
+}
+
+func formatMillis(ms int64) string {
+	return time.UnixMilli(ms).UTC().Format(time.RFC3339)
+}
+
+func formatMillisPtr(ms *int64) string {
+	if ms == nil || *ms <= 0 {
+		return ""
+	}
+	return formatMillis(*ms)
+}
+
+func formatIntPtr(n *int) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.Itoa(*n)
+}
+
+func writeSchema(w io.Writer, schema catalogapi.Schema) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, f := range schema.Fields {
+		required := "optional"
+		if f.Required {
+			required = "required"
+		}
+		fmt.Fprintf(tw, "  %d\t%s\t%s\t%s\n", f.Id, f.Name, formatType(f.Type), required)
+	}
+	tw.Flush()
+}
+
+func writeProperties(w io.Writer, props map[string]string) {
+	writeMap(w, "Properties", props, false)
+}
+
+// writeMap writes m as sorted key=value lines under title. With showEmpty,
+// an empty map is written as "(none)" rather than left out.
+func writeMap(w io.Writer, title string, m map[string]string, showEmpty bool) {
+	if len(m) == 0 {
+		if showEmpty {
+			fmt.Fprintf(w, "%s: (none)\n", title)
+		}
+		return
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fmt.Fprintf(w, "%s:\n", title)
+	for _, k := range keys {
+		fmt.Fprintf(w, "  %s=%s\n", k, m[k])
+	}
+}
+
+func derefMap(m *map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	return *m
+}
+
+var identifierColumns = []output.Column[catalogapi.TableIdentifier]{
+	{Header: "NAMESPACE", Value: func(id catalogapi.TableIdentifier) string { return formatNamespace(id.Namespace) }},
+	{Header: "NAME", Value: func(id catalogapi.TableIdentifier) string { return id.Name }},
+}
+
+// identifierRows fills in the rows of a table or view listing. The name
+// format prints fully qualified names.
+func identifierRows(r *output.Result, items []catalogapi.TableIdentifier) {
+	output.Rows(r, items, identifierColumns)
+	r.Names = make([]string, len(items))
+	for i, id := range items {
+		r.Names[i] = formatNamespace(id.Namespace) + "." + id.Name
+	}
+}
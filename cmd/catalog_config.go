@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/goravaa/apache-polaris-cli/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var catalogConfigCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show the server's catalog config",
+	Long: `Fetch GET /v1/config and show the defaults, overrides and endpoints the
+server advertises for a warehouse. Without --warehouse, POLARIS_WAREHOUSE
+or a warehouse in the profile, the catalog prefix is used as the warehouse,
+as Polaris names a catalog's warehouse after the catalog.
+
+When a warehouse is set, with --warehouse, POLARIS_WAREHOUSE or
+'config set --warehouse', other catalog commands use the same config: the
+catalog prefix comes from overrides.prefix unless --prefix or
+POLARIS_CATALOG_PREFIX is given, and calls to routes missing from the
+endpoints list fail before they are sent. Without a warehouse, the config is
+requested for the catalog prefix and the same check applies whenever the
+server advertises an endpoints list.
+
+Examples:
+  polaris catalog config --warehouse my_catalog
+  polaris catalog config --prefix my_catalog`,
+	Args: cobra.NoArgs,
+	RunE: runCatalogConfig,
+}
+
+var catalogConfigHeaders = []string{"SECTION", "KEY", "VALUE"}
+
+func init() {
+	catalogCmd.AddCommand(catalogConfigCmd)
+	setColumns(catalogConfigCmd, catalogConfigHeaders)
+}
+
+func runCatalogConfig(cmd *cobra.Command, args []string) error {
+	client, cfg, err := newCatalogClient()
+	if err != nil {
+		return err
+	}
+
+	// Polaris requires a warehouse and names it after the catalog, so the
+	// catalog prefix is asked for when no warehouse is set.
+	warehouse := cfg.Warehouse
+	if warehouse == "" {
+		if warehouse, err = resolveCatalogPrefix(cfg); err != nil {
+			return err
+		}
+	}
+
+	catalogConfig, err := getCatalogConfig(cmd.Context(), client, warehouse)
+	if err != nil {
+		return err
+	}
+
+	var endpoints []string
+	if catalogConfig.Endpoints != nil {
+		endpoints = append(endpoints, *catalogConfig.Endpoints...)
+		sort.Strings(endpoints)
+	}
+
+	result := &output.Result{
+		Object:  catalogConfig,
+		Headers: catalogConfigHeaders,
+		Names:   endpoints,
+		Text: func(w io.Writer) error {
+			fmt.Fprintf(w, "Warehouse: %s\n", warehouse)
+			writeMap(w, "Defaults", catalogConfig.Defaults, true)
+			writeMap(w, "Overrides", catalogConfig.Overrides, true)
+			if catalogConfig.Endpoints == nil {
+				fmt.Fprintln(w, "Endpoints: (not advertised; the Iceberg REST default set is assumed)")
+				return nil
+			}
+			fmt.Fprintln(w, "Endpoints:")
+			for _, e := range endpoints {
+				fmt.Fprintf(w, "  %s\n", e)
+			}
+			return nil
+		},
+	}
+	for _, section := range []struct {
+		name   string
+		values map[string]string
+	}{
+		{"defaults", catalogConfig.Defaults},
+		{"overrides", catalogConfig.Overrides},
+	} {
+		keys := make([]string, 0, len(section.values))
+		for k := range section.values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			result.Rows = append(result.Rows, []string{section.name, k, section.values[k]})
+		}
+	}
+	for _, e := range endpoints {
+		result.Rows = append(result.Rows, []string{"endpoints", e, ""})
+	}
+
+	return printResult(result)
+}
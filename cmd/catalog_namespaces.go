@@ -3,12 +3,18 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
 
+	"github.com/goravaa/apache-polaris-cli/pkg/api"
 	catalogapi "github.com/goravaa/apache-polaris-cli/pkg/api/openapi/catalog"
+	"github.com/goravaa/apache-polaris-cli/pkg/output"
 	"github.com/spf13/cobra"
 )
 
-var namespaceProperties []string
+var (
+	namespaceProperties []string
+	namespacePages      pageFlags
+)
 
 var catalogNamespacesCmd = &cobra.Command{
 	Use:   "namespaces",
@@ -33,7 +39,12 @@ func init() {
 	catalogNamespacesCmd.AddCommand(catalogNamespacesListCmd)
 	catalogNamespacesCmd.AddCommand(catalogNamespacesCreateCmd)
 
+	namespacePages.register(catalogNamespacesListCmd)
+
 	catalogNamespacesCreateCmd.Flags().StringArrayVar(&namespaceProperties, "property", nil, "Namespace property key=value (repeatable)")
+
+	setColumns(catalogNamespacesListCmd, output.Headers(namespaceColumns))
+	setColumns(catalogNamespacesCreateCmd, output.Headers(namespaceColumns))
 }
 
 func runCatalogNamespacesList(cmd *cobra.Command, args []string) error {
@@ -42,29 +53,45 @@ func runCatalogNamespacesList(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	prefix, err := resolveCatalogPrefix(cfg)
-	if err != nil {
-		return err
-	}
-
-	resp, err := client.ListNamespacesWithResponse(context.Background(), catalogapi.Prefix(prefix), nil)
+	prefix, err := catalogTarget(cmd.Context(), client, cfg, api.EndpointListNamespaces)
 	if err != nil {
 		return err
 	}
-	if resp.JSON200 == nil {
-		return fmt.Errorf("request failed: %s", resp.Status())
-	}
 
-	if resp.JSON200.Namespaces == nil || len(*resp.JSON200.Namespaces) == 0 {
-		fmt.Println("(no namespaces)")
-		return nil
+	fetch := func(ctx context.Context, page api.PageRequest) (api.Page[catalogapi.Namespace], error) {
+		resp, err := client.ListNamespacesWithResponse(ctx, catalogapi.Prefix(prefix), &catalogapi.ListNamespacesParams{
+			PageToken: page.Token,
+			PageSize:  page.Size,
+		})
+		if err != nil {
+			return api.Page[catalogapi.Namespace]{}, err
+		}
+		if resp.JSON200 == nil {
+			return api.Page[catalogapi.Namespace]{}, api.ResponseError(resp.HTTPResponse, resp.Body)
+		}
+
+		result := api.Page[catalogapi.Namespace]{}
+		if resp.JSON200.Namespaces != nil {
+			result.Items = *resp.JSON200.Namespaces
+		}
+		if resp.JSON200.NextPageToken != nil {
+			result.NextPageToken = *resp.JSON200.NextPageToken
+		}
+		return result, nil
 	}
 
-	for _, ns := range *resp.JSON200.Namespaces {
-		fmt.Println(formatNamespace(ns))
-	}
+	return listPages(cmd.Context(), &namespacePages, fetch, func(items []catalogapi.Namespace, next string) *output.Result {
+		result := &output.Result{
+			Object: catalogapi.ListNamespacesResponse{Namespaces: &items, NextPageToken: nextPageToken(next)},
+			Empty:  "(no namespaces)",
+		}
+		output.Rows(result, items, namespaceColumns)
+		return result
+	})
+}
 
-	return nil
+var namespaceColumns = []output.Column[catalogapi.Namespace]{
+	{Header: "NAMESPACE", Value: func(ns catalogapi.Namespace) string { return formatNamespace(ns) }},
 }
 
 func runCatalogNamespacesCreate(cmd *cobra.Command, args []string) error {
@@ -73,7 +100,7 @@ func runCatalogNamespacesCreate(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	prefix, err := resolveCatalogPrefix(cfg)
+	prefix, err := catalogTarget(cmd.Context(), client, cfg, api.EndpointCreateNamespace)
 	if err != nil {
 		return err
 	}
@@ -95,14 +122,21 @@ func runCatalogNamespacesCreate(cmd *cobra.Command, args []string) error {
 		req.Properties = &props
 	}
 
-	resp, err := client.CreateNamespaceWithResponse(context.Background(), catalogapi.Prefix(prefix), req)
+	resp, err := client.CreateNamespaceWithResponse(cmd.Context(), catalogapi.Prefix(prefix), req)
 	if err != nil {
 		return err
 	}
 	if resp.JSON200 == nil {
-		return fmt.Errorf("request failed: %s", resp.Status())
+		return api.ResponseError(resp.HTTPResponse, resp.Body)
 	}
 
-	fmt.Printf("Created namespace %s\n", formatNamespace(parts))
-	return nil
+	result := &output.Result{
+		Object: resp.JSON200,
+		Text: func(w io.Writer) error {
+			_, err := fmt.Fprintf(w, "Created namespace %s\n", formatNamespace(parts))
+			return err
+		},
+	}
+	output.Rows(result, []catalogapi.Namespace{resp.JSON200.Namespace}, namespaceColumns)
+	return printResult(result)
 }
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/goravaa/apache-polaris-cli/pkg/api"
+	catalogapi "github.com/goravaa/apache-polaris-cli/pkg/api/openapi/catalog"
+	"github.com/goravaa/apache-polaris-cli/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	policyNamespace string
+	policyType      string
+	policyPages     pageFlags
+)
+
+var catalogPoliciesCmd = &cobra.Command{
+	Use:   "policies",
+	Short: "Policy operations",
+}
+
+var catalogPoliciesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List policies in a namespace",
+	Long: `List the policies defined in a namespace.
+
+Use --type to list only policies of one type, e.g. system.data-compaction.`,
+	RunE: runCatalogPoliciesList,
+}
+
+func init() {
+	catalogCmd.AddCommand(catalogPoliciesCmd)
+	catalogPoliciesCmd.AddCommand(catalogPoliciesListCmd)
+
+	catalogPoliciesListCmd.Flags().StringVar(&policyNamespace, "namespace", "", "Namespace (dot- or slash-separated)")
+	catalogPoliciesListCmd.Flags().StringVar(&policyType, "type", "", "Only list policies of this type")
+	policyPages.register(catalogPoliciesListCmd)
+
+	setColumns(catalogPoliciesListCmd, output.Headers(policyColumns))
+}
+
+var policyColumns = []output.Column[catalogapi.PolicyIdentifier]{
+	{Header: "NAMESPACE", Value: func(id catalogapi.PolicyIdentifier) string { return formatNamespace(id.Namespace) }},
+	{Header: "NAME", Value: func(id catalogapi.PolicyIdentifier) string { return id.Name }},
+}
+
+func runCatalogPoliciesList(cmd *cobra.Command, args []string) error {
+	if policyNamespace == "" {
+		return usageErrorf("--namespace is required")
+	}
+
+	client, cfg, err := newCatalogClient()
+	if err != nil {
+		return err
+	}
+
+	prefix, err := catalogTarget(cmd.Context(), client, cfg, api.EndpointListPolicies)
+	if err != nil {
+		return err
+	}
+
+	parts, err := parseNamespaceArg(policyNamespace)
+	if err != nil {
+		return err
+	}
+
+	params := catalogapi.ListPoliciesParams{}
+	if policyType != "" {
+		params.PolicyType = &policyType
+	}
+
+	nsPath := namespacePath(parts)
+	fetch := func(ctx context.Context, page api.PageRequest) (api.Page[catalogapi.PolicyIdentifier], error) {
+		params.PageToken = page.Token
+		params.PageSize = page.Size
+		resp, err := client.ListPoliciesWithResponse(
+			ctx,
+			catalogapi.Prefix(prefix),
+			catalogapi.NamespaceString(nsPath),
+			&params,
+		)
+		if err != nil {
+			return api.Page[catalogapi.PolicyIdentifier]{}, err
+		}
+		if resp.JSON200 == nil {
+			return api.Page[catalogapi.PolicyIdentifier]{}, api.ResponseError(resp.HTTPResponse, resp.Body)
+		}
+
+		result := api.Page[catalogapi.PolicyIdentifier]{}
+		if resp.JSON200.Identifiers != nil {
+			result.Items = *resp.JSON200.Identifiers
+		}
+		if resp.JSON200.NextPageToken != nil {
+			result.NextPageToken = *resp.JSON200.NextPageToken
+		}
+		return result, nil
+	}
+
+	return listPages(cmd.Context(), &policyPages, fetch, func(items []catalogapi.PolicyIdentifier, next string) *output.Result {
+		result := &output.Result{
+			Object: catalogapi.ListPoliciesResponse{Identifiers: &items, NextPageToken: nextPageToken(next)},
+			Empty:  "(no policies)",
+		}
+		output.Rows(result, items, policyColumns)
+		result.Names = make([]string, len(items))
+		for i, id := range items {
+			result.Names[i] = formatNamespace(id.Namespace) + "." + id.Name
+		}
+		return result
+	})
+}
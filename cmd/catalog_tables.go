@@ -3,12 +3,19 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
+	"strconv"
 
+	"github.com/goravaa/apache-polaris-cli/pkg/api"
 	catalogapi "github.com/goravaa/apache-polaris-cli/pkg/api/openapi/catalog"
+	"github.com/goravaa/apache-polaris-cli/pkg/output"
 	"github.com/spf13/cobra"
 )
 
-var tableNamespace string
+var (
+	tableNamespace string
+	tablePages     pageFlags
+)
 
 var catalogTablesCmd = &cobra.Command{
 	Use:   "tables",
@@ -21,16 +28,46 @@ var catalogTablesListCmd = &cobra.Command{
 	RunE:  runCatalogTablesList,
 }
 
+var catalogTablesGetCmd = &cobra.Command{
+	Use:   "get <table>",
+	Short: "Show a table's metadata",
+	Long: `Load a table and show its location, current snapshot and schema.
+
+Loaded metadata is cached under the config directory and revalidated with
+the server's ETag, so repeated calls for an unchanged table are cheap. Use
+--refresh to reload it or --no-cache to bypass the cache.
+
+With --watch the table is loaded again every --interval and changes to it,
+such as a new snapshot, are printed as they happen.
+
+Examples:
+  polaris catalog tables get t1 --namespace db
+  polaris catalog tables get t1 --namespace db -o go-template='{{index . "metadata-location"}}'
+  polaris catalog tables get t1 --namespace db --watch --interval 10s`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCatalogTablesGet,
+}
+
 func init() {
 	catalogCmd.AddCommand(catalogTablesCmd)
 	catalogTablesCmd.AddCommand(catalogTablesListCmd)
+	catalogTablesCmd.AddCommand(catalogTablesGetCmd)
 
 	catalogTablesListCmd.Flags().StringVar(&tableNamespace, "namespace", "", "Namespace (dot- or slash-separated)")
+	tablePages.register(catalogTablesListCmd)
+
+	catalogTablesGetCmd.Flags().StringVar(&tableNamespace, "namespace", "", "Namespace (dot- or slash-separated)")
+	addWatchFlags(catalogTablesGetCmd)
+
+	setColumns(catalogTablesListCmd, output.Headers(identifierColumns))
+	setColumns(catalogTablesGetCmd, tableHeaders)
 }
 
+var tableHeaders = []string{"TABLE", "UUID", "FORMAT VERSION", "LOCATION", "CURRENT SNAPSHOT"}
+
 func runCatalogTablesList(cmd *cobra.Command, args []string) error {
 	if tableNamespace == "" {
-		return fmt.Errorf("--namespace is required")
+		return usageErrorf("--namespace is required")
 	}
 
 	client, cfg, err := newCatalogClient()
@@ -38,7 +75,7 @@ func runCatalogTablesList(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	prefix, err := resolveCatalogPrefix(cfg)
+	prefix, err := catalogTarget(cmd.Context(), client, cfg, api.EndpointListTables)
 	if err != nil {
 		return err
 	}
@@ -49,27 +86,130 @@ func runCatalogTablesList(cmd *cobra.Command, args []string) error {
 	}
 
 	nsPath := namespacePath(parts)
-	resp, err := client.ListTablesWithResponse(
-		context.Background(),
-		catalogapi.Prefix(prefix),
-		catalogapi.NamespaceString(nsPath),
-		nil,
-	)
+	fetch := func(ctx context.Context, page api.PageRequest) (api.Page[catalogapi.TableIdentifier], error) {
+		resp, err := client.ListTablesWithResponse(
+			ctx,
+			catalogapi.Prefix(prefix),
+			catalogapi.NamespaceString(nsPath),
+			&catalogapi.ListTablesParams{
+				PageToken: page.Token,
+				PageSize:  page.Size,
+			},
+		)
+		if err != nil {
+			return api.Page[catalogapi.TableIdentifier]{}, err
+		}
+		if resp.JSON200 == nil {
+			return api.Page[catalogapi.TableIdentifier]{}, api.ResponseError(resp.HTTPResponse, resp.Body)
+		}
+
+		result := api.Page[catalogapi.TableIdentifier]{}
+		if resp.JSON200.Identifiers != nil {
+			result.Items = *resp.JSON200.Identifiers
+		}
+		if resp.JSON200.NextPageToken != nil {
+			result.NextPageToken = *resp.JSON200.NextPageToken
+		}
+		return result, nil
+	}
+
+	return listPages(cmd.Context(), &tablePages, fetch, func(items []catalogapi.TableIdentifier, next string) *output.Result {
+		result := &output.Result{
+			Object: catalogapi.ListTablesResponse{Identifiers: &items, NextPageToken: nextPageToken(next)},
+			Empty:  "(no tables)",
+		}
+		identifierRows(result, items)
+		return result
+	})
+}
+
+func runCatalogTablesGet(cmd *cobra.Command, args []string) error {
+	if tableNamespace == "" {
+		return usageErrorf("--namespace is required")
+	}
+
+	client, cfg, err := newCatalogClient()
 	if err != nil {
 		return err
 	}
-	if resp.JSON200 == nil {
-		return fmt.Errorf("request failed: %s", resp.Status())
-	}
 
-	if resp.JSON200.Identifiers == nil || len(*resp.JSON200.Identifiers) == 0 {
-		fmt.Println("(no tables)")
-		return nil
+	prefix, err := catalogTarget(cmd.Context(), client, cfg, api.EndpointLoadTable)
+	if err != nil {
+		return err
 	}
 
-	for _, ident := range *resp.JSON200.Identifiers {
-		fmt.Printf("%s.%s\n", formatNamespace(ident.Namespace), ident.Name)
+	parts, err := parseNamespaceArg(tableNamespace)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	return showResult(cmd.Context(), func(ctx context.Context) (*output.Result, error) {
+		resp, err := client.LoadTableWithResponse(
+			ctx,
+			catalogapi.Prefix(prefix),
+			catalogapi.NamespaceString(namespacePath(parts)),
+			catalogapi.Table(args[0]),
+			nil,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if resp.JSON200 == nil {
+			return nil, api.ResponseError(resp.HTTPResponse, resp.Body)
+		}
+
+		loaded := resp.JSON200
+		name := formatNamespace(parts) + "." + args[0]
+		m := loaded.Metadata
+		location := ""
+		if m.Location != nil {
+			location = *m.Location
+		}
+		snapshot := ""
+		if m.CurrentSnapshotId != nil && *m.CurrentSnapshotId >= 0 {
+			snapshot = strconv.FormatInt(*m.CurrentSnapshotId, 10)
+		}
+		result := &output.Result{
+			Object:  loaded,
+			Headers: tableHeaders,
+			Rows:    [][]string{{name, m.TableUuid, strconv.Itoa(m.FormatVersion), location, snapshot}},
+			Text: func(w io.Writer) error {
+				describeTable(w, name, loaded)
+				return nil
+			},
+		}
+		return result, nil
+	})
+}
+
+func describeTable(w io.Writer, name string, loaded *catalogapi.LoadTableResult) {
+	m := loaded.Metadata
+	fmt.Fprintf(w, "Table: %s\n", name)
+	fmt.Fprintf(w, "UUID: %s\n", m.TableUuid)
+	fmt.Fprintf(w, "Format Version: %d\n", m.FormatVersion)
+	if m.Location != nil {
+		fmt.Fprintf(w, "Location: %s\n", *m.Location)
+	}
+	if loaded.MetadataLocation != nil {
+		fmt.Fprintf(w, "Metadata Location: %s\n", *loaded.MetadataLocation)
+	}
+	if m.LastUpdatedMs != nil {
+		fmt.Fprintf(w, "Last Updated: %s\n", formatMillis(*m.LastUpdatedMs))
+	}
+	if m.CurrentSnapshotId != nil && *m.CurrentSnapshotId >= 0 {
+		fmt.Fprintf(w, "Current Snapshot: %d\n", *m.CurrentSnapshotId)
+	} else {
+		fmt.Fprintln(w, "Current Snapshot: (none)")
+	}
+	if m.Schemas != nil {
+		for _, schema := range *m.Schemas {
+			if m.CurrentSchemaId != nil && schema.SchemaId != nil && *schema.SchemaId != *m.CurrentSchemaId {
+				continue
+			}
+			fmt.Fprintln(w, "Schema:")
+			writeSchema(w, schema)
+			break
+		}
+	}
+	writeProperties(w, derefMap(m.Properties))
 }
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/goravaa/apache-polaris-cli/pkg/api"
+	catalogapi "github.com/goravaa/apache-polaris-cli/pkg/api/openapi/catalog"
+	"github.com/goravaa/apache-polaris-cli/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	viewNamespace string
+	viewPages     pageFlags
+)
+
+var catalogViewsCmd = &cobra.Command{
+	Use:   "views",
+	Short: "View operations",
+}
+
+var catalogViewsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List views in a namespace",
+	RunE:  runCatalogViewsList,
+}
+
+var catalogViewsGetCmd = &cobra.Command{
+	Use:   "get <view>",
+	Short: "Show a view's metadata",
+	Long: `Load a view and show its location, current version and SQL.
+
+Loaded metadata is cached under the config directory and revalidated with
+the server's ETag. Use --refresh to reload it or --no-cache to bypass the
+cache.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCatalogViewsGet,
+}
+
+func init() {
+	catalogCmd.AddCommand(catalogViewsCmd)
+	catalogViewsCmd.AddCommand(catalogViewsListCmd)
+	catalogViewsCmd.AddCommand(catalogViewsGetCmd)
+
+	catalogViewsListCmd.Flags().StringVar(&viewNamespace, "namespace", "", "Namespace (dot- or slash-separated)")
+	viewPages.register(catalogViewsListCmd)
+
+	catalogViewsGetCmd.Flags().StringVar(&viewNamespace, "namespace", "", "Namespace (dot- or slash-separated)")
+	addWatchFlags(catalogViewsGetCmd)
+
+	setColumns(catalogViewsListCmd, output.Headers(identifierColumns))
+	setColumns(catalogViewsGetCmd, viewHeaders)
+}
+
+var viewHeaders = []string{"VIEW", "UUID", "FORMAT VERSION", "LOCATION", "CURRENT VERSION"}
+
+func runCatalogViewsList(cmd *cobra.Command, args []string) error {
+	if viewNamespace == "" {
+		return usageErrorf("--namespace is required")
+	}
+
+	client, cfg, err := newCatalogClient()
+	if err != nil {
+		return err
+	}
+
+	prefix, err := catalogTarget(cmd.Context(), client, cfg, api.EndpointListViews)
+	if err != nil {
+		return err
+	}
+
+	parts, err := parseNamespaceArg(viewNamespace)
+	if err != nil {
+		return err
+	}
+
+	nsPath := namespacePath(parts)
+	fetch := func(ctx context.Context, page api.PageRequest) (api.Page[catalogapi.TableIdentifier], error) {
+		resp, err := client.ListViewsWithResponse(
+			ctx,
+			catalogapi.Prefix(prefix),
+			catalogapi.NamespaceString(nsPath),
+			&catalogapi.ListViewsParams{
+				PageToken: page.Token,
+				PageSize:  page.Size,
+			},
+		)
+		if err != nil {
+			return api.Page[catalogapi.TableIdentifier]{}, err
+		}
+		if resp.JSON200 == nil {
+			return api.Page[catalogapi.TableIdentifier]{}, api.ResponseError(resp.HTTPResponse, resp.Body)
+		}
+
+		result := api.Page[catalogapi.TableIdentifier]{}
+		if resp.JSON200.Identifiers != nil {
+			result.Items = *resp.JSON200.Identifiers
+		}
+		if resp.JSON200.NextPageToken != nil {
+			result.NextPageToken = *resp.JSON200.NextPageToken
+		}
+		return result, nil
+	}
+
+	return listPages(cmd.Context(), &viewPages, fetch, func(items []catalogapi.TableIdentifier, next string) *output.Result {
+		result := &output.Result{
+			Object: catalogapi.ListTablesResponse{Identifiers: &items, NextPageToken: nextPageToken(next)},
+			Empty:  "(no views)",
+		}
+		identifierRows(result, items)
+		return result
+	})
+}
+
+func runCatalogViewsGet(cmd *cobra.Command, args []string) error {
+	if viewNamespace == "" {
+		return usageErrorf("--namespace is required")
+	}
+
+	client, cfg, err := newCatalogClient()
+	if err != nil {
+		return err
+	}
+
+	prefix, err := catalogTarget(cmd.Context(), client, cfg, api.EndpointLoadView)
+	if err != nil {
+		return err
+	}
+
+	parts, err := parseNamespaceArg(viewNamespace)
+	if err != nil {
+		return err
+	}
+
+	return showResult(cmd.Context(), func(ctx context.Context) (*output.Result, error) {
+		resp, err := client.LoadViewWithResponse(
+			ctx,
+			catalogapi.Prefix(prefix),
+			catalogapi.NamespaceString(namespacePath(parts)),
+			catalogapi.View(args[0]),
+		)
+		if err != nil {
+			return nil, err
+		}
+		if resp.JSON200 == nil {
+			return nil, api.ResponseError(resp.HTTPResponse, resp.Body)
+		}
+
+		loaded := resp.JSON200
+		name := formatNamespace(parts) + "." + args[0]
+		m := loaded.Metadata
+		result := &output.Result{
+			Object:  loaded,
+			Headers: viewHeaders,
+			Rows:    [][]string{{name, m.ViewUuid, strconv.Itoa(m.FormatVersion), m.Location, strconv.Itoa(m.CurrentVersionId)}},
+			Text: func(w io.Writer) error {
+				describeView(w, name, loaded)
+				return nil
+			},
+		}
+		return result, nil
+	})
+}
+
+func describeView(w io.Writer, name string, loaded *catalogapi.LoadViewResult) {
+	m := loaded.Metadata
+	fmt.Fprintf(w, "View: %s\n", name)
+	fmt.Fprintf(w, "UUID: %s\n", m.ViewUuid)
+	fmt.Fprintf(w, "Format Version: %d\n", m.FormatVersion)
+	fmt.Fprintf(w, "Location: %s\n", m.Location)
+	fmt.Fprintf(w, "Metadata Location: %s\n", loaded.MetadataLocation)
+
+	for _, version := range m.Versions {
+		if version.VersionId != m.CurrentVersionId {
+			continue
+		}
+		fmt.Fprintf(w, "Current Version: %d (%s)\n", version.VersionId, formatMillis(version.TimestampMs))
+		for _, schema := range m.Schemas {
+			if schema.SchemaId != nil && *schema.SchemaId == version.SchemaId {
+				fmt.Fprintln(w, "Schema:")
+				writeSchema(w, schema)
+			}
+		}
+		for _, representation := range version.Representations {
+			if sql, err := representation.AsSQLViewRepresentation(); err == nil && sql.Sql != "" {
+				fmt.Fprintf(w, "SQL (%s):\n  %s\n", sql.Dialect, sql.Sql)
+			}
+		}
+	}
+	writeProperties(w, derefMap(m.Properties))
+}
@@ -1,11 +1,20 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 
+	"github.com/goravaa/apache-polaris-cli/pkg/api"
 	managementapi "github.com/goravaa/apache-polaris-cli/pkg/api/openapi/management"
+	"github.com/goravaa/apache-polaris-cli/pkg/output"
 	"github.com/spf13/cobra"
 )
 
@@ -16,6 +25,13 @@ var (
 	catalogDefaultBaseLocation string
 	catalogAllowedLocations    []string
 	catalogProperties          []string
+
+	catalogSetProperties     []string
+	catalogRemoveProperties  []string
+	catalogAddLocations      []string
+	catalogRemoveLocations   []string
+	catalogUpdateStorageType string
+	catalogStorageConfig     []string
 )
 
 var catalogsCmd = &cobra.Command{
@@ -26,7 +42,26 @@ var catalogsCmd = &cobra.Command{
 var catalogsListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List catalogs",
-	RunE:  runCatalogsList,
+	Long: `List the catalogs of the realm.
+
+Wide and csv output add the less common columns, the entity version and
+allowed locations, and --columns, --sort-by and --no-headers shape the
+table. The json and yaml forms are the response of the Polaris API, and
+fields can be extracted from it with a go-template or jsonpath, inline or
+with --template-file. Go templates can also use namespace (join namespace
+levels with dots), join, timestamp (format epoch milliseconds or RFC 3339
+times) and json.
+
+With --watch the catalogs are listed again every --interval, and only
+changes are printed, marked + (added), - (removed) or ~ (changed). With
+-o json each change is one line, {"type": "ADDED", "object": {...}}.
+
+Examples:
+  polaris catalogs list --columns name,base-location --sort-by updated
+  polaris catalogs list -o jsonpath='{.catalogs[*].name}'
+  polaris catalogs list -o go-template='{{range .catalogs}}{{.name}} {{timestamp .createTimestamp}}{{"\n"}}{{end}}'
+  polaris catalogs list --watch`,
+	RunE: runCatalogsList,
 }
 
 var catalogsDescribeCmd = &cobra.Command{
@@ -41,6 +76,28 @@ var catalogsCreateCmd = &cobra.Command{
 	RunE:  runCatalogsCreate,
 }
 
+var catalogsUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Update a catalog's properties or storage config",
+	Long: `Update a catalog's properties, default base location or storage config.
+
+The catalog is read first and the update is sent with its entity version,
+so it fails with a conflict (exit code 4) instead of overwriting the changes
+if another update was made in between. Settings of the storage config that
+are not changed, such as an S3 role ARN, are kept.
+
+A --storage-config value takes the type of the setting's current value, so
+pathStyleAccess=true sends a boolean. The value of a setting the catalog does
+not have yet is parsed as JSON if it is valid JSON, and is a string
+otherwise; quote it, as externalId='"123"', to send digits as a string.
+
+Examples:
+  polaris catalogs update --name my_catalog --set-property owner=data-eng
+  polaris catalogs update --name my_catalog --storage-config roleArn=arn:aws:iam::123456789012:role/polaris
+  polaris catalogs update --name my_catalog --storage-config pathStyleAccess=true`,
+	RunE: runCatalogsUpdate,
+}
+
 var catalogsDeleteCmd = &cobra.Command{
 	Use:   "delete",
 	Short: "Delete a catalog",
@@ -52,10 +109,14 @@ func init() {
 	catalogsCmd.AddCommand(catalogsListCmd)
 	catalogsCmd.AddCommand(catalogsDescribeCmd)
 	catalogsCmd.AddCommand(catalogsCreateCmd)
+	catalogsCmd.AddCommand(catalogsUpdateCmd)
 	catalogsCmd.AddCommand(catalogsDeleteCmd)
 
 	catalogsDescribeCmd.Flags().StringVar(&catalogName, "name", "", "Catalog name (required)")
 
+	addWatchFlags(catalogsListCmd)
+	addWatchFlags(catalogsDescribeCmd)
+
 	catalogsCreateCmd.Flags().StringVar(&catalogName, "name", "", "Catalog name (required)")
 	catalogsCreateCmd.Flags().StringVar(&catalogType, "type", "INTERNAL", "Catalog type: INTERNAL or EXTERNAL")
 	catalogsCreateCmd.Flags().StringVar(&catalogStorageType, "storage-type", "S3", "Storage type: S3, GCS, AZURE, FILE")
@@ -63,7 +124,21 @@ func init() {
 	catalogsCreateCmd.Flags().StringArrayVar(&catalogAllowedLocations, "allowed-location", nil, "Allowed location (repeatable)")
 	catalogsCreateCmd.Flags().StringArrayVar(&catalogProperties, "property", nil, "Catalog property key=value (repeatable)")
 
+	catalogsUpdateCmd.Flags().StringVar(&catalogName, "name", "", "Catalog name (required)")
+	catalogsUpdateCmd.Flags().StringArrayVar(&catalogSetProperties, "set-property", nil, "Set a catalog property key=value (repeatable)")
+	catalogsUpdateCmd.Flags().StringArrayVar(&catalogRemoveProperties, "remove-property", nil, "Remove a catalog property (repeatable)")
+	catalogsUpdateCmd.Flags().StringVar(&catalogDefaultBaseLocation, "default-base-location", "", "New default base location")
+	catalogsUpdateCmd.Flags().StringArrayVar(&catalogAddLocations, "add-allowed-location", nil, "Add an allowed location (repeatable)")
+	catalogsUpdateCmd.Flags().StringArrayVar(&catalogRemoveLocations, "remove-allowed-location", nil, "Remove an allowed location (repeatable)")
+	catalogsUpdateCmd.Flags().StringVar(&catalogUpdateStorageType, "storage-type", "", "New storage type: S3, GCS, AZURE, FILE; replaces the storage config")
+	catalogsUpdateCmd.Flags().StringArrayVar(&catalogStorageConfig, "storage-config", nil, "Set a storage config field key=value, such as roleArn=... or pathStyleAccess=true; a new field's value is parsed as JSON if valid (repeatable)")
+
 	catalogsDeleteCmd.Flags().StringVar(&catalogName, "name", "", "Catalog name (required)")
+
+	for _, cmd := range []*cobra.Command{catalogsListCmd, catalogsDescribeCmd, catalogsCreateCmd, catalogsUpdateCmd} {
+		setColumns(cmd, output.Headers(catalogColumns))
+	}
+	setColumns(catalogsDeleteCmd, actionHeaders)
 }
 
 func runCatalogsList(cmd *cobra.Command, args []string) error {
@@ -72,32 +147,27 @@ func runCatalogsList(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	resp, err := client.ListCatalogsWithResponse(context.Background())
-	if err != nil {
-		return err
-	}
-	if resp.JSON200 == nil {
-		return fmt.Errorf("request failed: %s", resp.Status())
-	}
-
-	if len(resp.JSON200.Catalogs) == 0 {
-		fmt.Println("(no catalogs)")
-		return nil
-	}
-
-	for _, c := range resp.JSON200.Catalogs {
-		fmt.Println(c.Name)
-	}
+	return showResult(cmd.Context(), func(ctx context.Context) (*output.Result, error) {
+		resp, err := client.ListCatalogsWithResponse(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if resp.JSON200 == nil {
+			return nil, api.ResponseError(resp.HTTPResponse, resp.Body)
+		}
 
-	return nil
+		result := &output.Result{Object: resp.JSON200, Empty: "(no catalogs)"}
+		output.Rows(result, resp.JSON200.Catalogs, catalogColumns)
+		return result, nil
+	})
 }
 
 func runCatalogsCreate(cmd *cobra.Command, args []string) error {
 	if catalogName == "" {
-		return fmt.Errorf("--name is required")
+		return usageErrorf("--name is required")
 	}
 	if catalogDefaultBaseLocation == "" {
-		return fmt.Errorf("--default-base-location is required")
+		return usageErrorf("--default-base-location is required")
 	}
 
 	typ, err := parseCatalogType(catalogType)
@@ -143,21 +213,29 @@ func runCatalogsCreate(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	resp, err := client.CreateCatalogWithResponse(context.Background(), req)
+	resp, err := client.CreateCatalogWithResponse(cmd.Context(), req)
 	if err != nil {
 		return err
 	}
 	if resp.JSON201 == nil {
-		return fmt.Errorf("request failed: %s", resp.Status())
+		return api.ResponseError(resp.HTTPResponse, resp.Body)
 	}
 
-	fmt.Printf("Created catalog %s\n", catalogName)
-	return nil
+	created := resp.JSON201
+	result := &output.Result{
+		Object: created,
+		Text: func(w io.Writer) error {
+			_, err := fmt.Fprintf(w, "Created catalog %s\n", created.Name)
+			return err
+		},
+	}
+	output.Rows(result, []managementapi.Catalog{*created}, catalogColumns)
+	return printResult(result)
 }
 
 func runCatalogsDescribe(cmd *cobra.Command, args []string) error {
 	if catalogName == "" {
-		return fmt.Errorf("--name is required")
+		return usageErrorf("--name is required")
 	}
 
 	client, _, err := newManagementClient()
@@ -165,40 +243,298 @@ func runCatalogsDescribe(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	resp, err := client.GetCatalogWithResponse(context.Background(), catalogName)
+	return showResult(cmd.Context(), func(ctx context.Context) (*output.Result, error) {
+		resp, err := client.GetCatalogWithResponse(ctx, catalogName)
+		if err != nil {
+			return nil, err
+		}
+		if resp.JSON200 == nil {
+			return nil, api.ResponseError(resp.HTTPResponse, resp.Body)
+		}
+
+		c := resp.JSON200
+		result := &output.Result{
+			Object: c,
+			Text: func(w io.Writer) error {
+				describeCatalog(w, c)
+				return nil
+			},
+		}
+		output.Rows(result, []managementapi.Catalog{*c}, catalogColumns)
+		return result, nil
+	})
+}
+
+var catalogColumns = []output.Column[managementapi.Catalog]{
+	{Header: "NAME", Value: func(c managementapi.Catalog) string { return c.Name }},
+	{Header: "TYPE", Value: func(c managementapi.Catalog) string { return string(c.Type) }},
+	{Header: "STORAGE TYPE", Value: func(c managementapi.Catalog) string { return string(c.StorageConfigInfo.StorageType) }},
+	{Header: "BASE LOCATION", Value: func(c managementapi.Catalog) string { return c.Properties.DefaultBaseLocation }},
+	{Header: "CREATED", Value: func(c managementapi.Catalog) string { return formatMillisPtr(c.CreateTimestamp) }},
+	{Header: "UPDATED", Value: func(c managementapi.Catalog) string { return formatMillisPtr(c.LastUpdateTimestamp) }},
+	{Header: "ENTITY VERSION", Wide: true, Value: func(c managementapi.Catalog) string { return formatIntPtr(c.EntityVersion) }},
+	{Header: "ALLOWED LOCATIONS", Wide: true, Value: func(c managementapi.Catalog) string {
+		if c.StorageConfigInfo.AllowedLocations == nil {
+			return ""
+		}
+		return strings.Join(*c.StorageConfigInfo.AllowedLocations, ",")
+	}},
+}
+
+func describeCatalog(w io.Writer, c *managementapi.Catalog) {
+	fmt.Fprintf(w, "Name: %s\n", c.Name)
+	fmt.Fprintf(w, "Type: %s\n", c.Type)
+	fmt.Fprintf(w, "Storage Type: %s\n", c.StorageConfigInfo.StorageType)
+	if c.Properties.DefaultBaseLocation != "" {
+		fmt.Fprintf(w, "Default Base Location: %s\n", c.Properties.DefaultBaseLocation)
+	}
+	if c.StorageConfigInfo.AllowedLocations != nil && len(*c.StorageConfigInfo.AllowedLocations) > 0 {
+		fmt.Fprintln(w, "Allowed Locations:")
+		for _, loc := range *c.StorageConfigInfo.AllowedLocations {
+			fmt.Fprintf(w, "  %s\n", loc)
+		}
+	}
+	writeProperties(w, c.Properties.AdditionalProperties)
+}
+
+// catalogUpdate is sent instead of managementapi.UpdateCatalogRequest,
+// whose StorageConfigInfo only has the fields common to all storage types
+// and would drop the others, such as roleArn or tenantId.
+type catalogUpdate struct {
+	CurrentEntityVersion *int              `json:"currentEntityVersion,omitempty"`
+	Properties           map[string]string `json:"properties,omitempty"`
+	StorageConfigInfo    map[string]any    `json:"storageConfigInfo,omitempty"`
+}
+
+func runCatalogsUpdate(cmd *cobra.Command, args []string) error {
+	if catalogName == "" {
+		return usageErrorf("--name is required")
+	}
+	setProps, err := parseProperties(catalogSetProperties)
+	if err != nil {
+		return err
+	}
+	storageFields, err := parseProperties(catalogStorageConfig)
+	if err != nil {
+		return err
+	}
+	for key := range storageFields {
+		if key == "storageType" || key == "allowedLocations" {
+			return usageErrorf("use --storage-type or --add-allowed-location/--remove-allowed-location to change %s", key)
+		}
+	}
+	for _, key := range catalogRemoveProperties {
+		if key == "default-base-location" {
+			return usageErrorf("default-base-location cannot be removed; use --default-base-location to change it")
+		}
+	}
+	var storageType managementapi.StorageConfigInfoStorageType
+	if catalogUpdateStorageType != "" {
+		if storageType, err = parseStorageType(catalogUpdateStorageType); err != nil {
+			return err
+		}
+	}
+
+	changeProps := len(setProps) > 0 || len(catalogRemoveProperties) > 0 || catalogDefaultBaseLocation != ""
+	changeStorage := storageType != "" || len(storageFields) > 0 || len(catalogAddLocations) > 0 || len(catalogRemoveLocations) > 0
+	if !changeProps && !changeStorage {
+		return usageErrorf("nothing to update; use --set-property, --remove-property, --default-base-location, --add-allowed-location, --remove-allowed-location, --storage-type or --storage-config")
+	}
+
+	client, _, err := newManagementClient()
+	if err != nil {
+		return err
+	}
+
+	current, err := client.GetCatalogWithResponse(cmd.Context(), catalogName)
+	if err != nil {
+		return err
+	}
+	if current.JSON200 == nil {
+		return api.ResponseError(current.HTTPResponse, current.Body)
+	}
+
+	if current.JSON200.EntityVersion == nil {
+		return fmt.Errorf("the server did not report an entity version for catalog %s, so the update cannot be checked against concurrent changes", catalogName)
+	}
+
+	update := catalogUpdate{CurrentEntityVersion: current.JSON200.EntityVersion}
+	if changeProps {
+		update.Properties = updatedCatalogProperties(current.JSON200, setProps)
+	}
+	if changeStorage {
+		if update.StorageConfigInfo, err = updatedStorageConfig(current.Body, storageType, storageFields); err != nil {
+			return err
+		}
+	}
+
+	body, err := json.Marshal(update)
+	if err != nil {
+		return err
+	}
+	// The update is conditional on the entity version, so it is sent once: a
+	// retry after a lost response would be rejected as a conflict.
+	resp, err := client.UpdateCatalogWithBodyWithResponse(api.WithoutRetry(cmd.Context()), catalogName, "application/json", bytes.NewReader(body))
 	if err != nil {
 		return err
 	}
 	if resp.JSON200 == nil {
-		return fmt.Errorf("request failed: %s", resp.Status())
+		err := api.ResponseError(resp.HTTPResponse, resp.Body)
+		var apiErr *api.APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict {
+			return fmt.Errorf("catalog %s was changed by another update after version %s was read; describe it and retry: %w",
+				catalogName, formatIntPtr(update.CurrentEntityVersion), err)
+		}
+		return err
 	}
 
-	c := resp.JSON200
-	fmt.Printf("Name: %s\n", c.Name)
-	fmt.Printf("Type: %s\n", c.Type)
-	fmt.Printf("Storage Type: %s\n", c.StorageConfigInfo.StorageType)
-	if c.Properties.DefaultBaseLocation != "" {
-		fmt.Printf("Default Base Location: %s\n", c.Properties.DefaultBaseLocation)
+	updated := resp.JSON200
+	result := &output.Result{
+		Object: updated,
+		Text: func(w io.Writer) error {
+			_, err := fmt.Fprintf(w, "Updated catalog %s (entity version %s)\n", updated.Name, formatIntPtr(updated.EntityVersion))
+			return err
+		},
 	}
-	if c.StorageConfigInfo.AllowedLocations != nil && len(*c.StorageConfigInfo.AllowedLocations) > 0 {
-		fmt.Println("Allowed Locations:")
-		for _, loc := range *c.StorageConfigInfo.AllowedLocations {
-			fmt.Printf("  %s\n", loc)
+	output.Rows(result, []managementapi.Catalog{*updated}, catalogColumns)
+	return printResult(result)
+}
+
+// updatedCatalogProperties returns the full property map to send, since an
+// update replaces the catalog's properties rather than merging them.
+func updatedCatalogProperties(c *managementapi.Catalog, set map[string]string) map[string]string {
+	props := make(map[string]string, len(c.Properties.AdditionalProperties)+len(set)+1)
+	for k, v := range c.Properties.AdditionalProperties {
+		props[k] = v
+	}
+	props["default-base-location"] = c.Properties.DefaultBaseLocation
+	for _, k := range catalogRemoveProperties {
+		delete(props, k)
+	}
+	for k, v := range set {
+		props[k] = v
+	}
+	if catalogDefaultBaseLocation != "" {
+		props["default-base-location"] = catalogDefaultBaseLocation
+	}
+	return props
+}
+
+// updatedStorageConfig applies the storage flags to the storage config in
+// the raw GetCatalog response. A new storage type keeps only the allowed
+// locations, because the other settings of the old type do not apply to it.
+func updatedStorageConfig(catalogBody []byte, storageType managementapi.StorageConfigInfoStorageType, fields map[string]string) (map[string]any, error) {
+	var raw struct {
+		StorageConfigInfo map[string]any `json:"storageConfigInfo"`
+	}
+	dec := json.NewDecoder(bytes.NewReader(catalogBody))
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to decode catalog: %w", err)
+	}
+	storage := raw.StorageConfigInfo
+	if storage == nil {
+		storage = map[string]any{}
+	}
+
+	var locations []string
+	if list, ok := storage["allowedLocations"].([]any); ok {
+		for _, loc := range list {
+			if s, ok := loc.(string); ok {
+				locations = append(locations, s)
+			}
+		}
+	}
+	if storageType != "" && storageType != managementapi.StorageConfigInfoStorageType(fmt.Sprint(storage["storageType"])) {
+		storage = map[string]any{"storageType": storageType}
+	}
+
+	for _, loc := range catalogRemoveLocations {
+		i := slices.Index(locations, loc)
+		if i < 0 {
+			return nil, fmt.Errorf("catalog %s has no allowed location %q", catalogName, loc)
+		}
+		locations = slices.Delete(locations, i, i+1)
+	}
+	for _, loc := range catalogAddLocations {
+		if !slices.Contains(locations, loc) {
+			locations = append(locations, loc)
+		}
+	}
+	storage["allowedLocations"] = locations
+	for k, v := range fields {
+		value, err := storageConfigValue(k, v, storage[k])
+		if err != nil {
+			return nil, err
 		}
+		storage[k] = value
 	}
-	if len(c.Properties.AdditionalProperties) > 0 {
-		fmt.Println("Properties:")
-		for k, v := range c.Properties.AdditionalProperties {
-			fmt.Printf("  %s=%s\n", k, v)
+	return storage, nil
+}
+
+// storageConfigValue converts a --storage-config value to the JSON value to
+// send. A setting the catalog already has keeps the type of its current
+// value, so roleArn stays a string and pathStyleAccess a boolean. A new
+// setting is parsed as JSON if it is valid JSON, such as true or ["a","b"],
+// and is taken as a string otherwise.
+func storageConfigValue(key, value string, current any) (any, error) {
+	switch current.(type) {
+	case string:
+		return value, nil
+	case bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, usageErrorf("--storage-config %s must be true or false, got %q", key, value)
 		}
+		return b, nil
+	case json.Number:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return nil, usageErrorf("--storage-config %s must be a number, got %q", key, value)
+		}
+		return json.Number(value), nil
+	}
+
+	if !json.Valid([]byte(value)) {
+		if current != nil {
+			return nil, usageErrorf("--storage-config %s must be JSON of the same kind as its current value %s", key, storageJSON(current))
+		}
+		return value, nil
+	}
+	dec := json.NewDecoder(strings.NewReader(value))
+	dec.UseNumber()
+	var parsed any
+	if err := dec.Decode(&parsed); err != nil {
+		return nil, err
 	}
+	if current != nil && !sameJSONKind(current, parsed) {
+		return nil, usageErrorf("--storage-config %s must be JSON of the same kind as its current value %s", key, storageJSON(current))
+	}
+	return parsed, nil
+}
 
-	return nil
+func sameJSONKind(a, b any) bool {
+	switch a.(type) {
+	case []any:
+		_, ok := b.([]any)
+		return ok
+	case map[string]any:
+		_, ok := b.(map[string]any)
+		return ok
+	}
+	return false
+}
+
+func storageJSON(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
 }
 
 func runCatalogsDelete(cmd *cobra.Command, args []string) error {
 	if catalogName == "" {
-		return fmt.Errorf("--name is required")
+		return usageErrorf("--name is required")
 	}
 
 	client, _, err := newManagementClient()
@@ -206,16 +542,15 @@ func runCatalogsDelete(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	resp, err := client.DeleteCatalogWithResponse(context.Background(), catalogName)
+	resp, err := client.DeleteCatalogWithResponse(cmd.Context(), catalogName)
 	if err != nil {
 		return err
 	}
 	if resp.StatusCode() < 200 || resp.StatusCode() >= 300 {
-		return fmt.Errorf("request failed: %s", resp.Status())
+		return api.ResponseError(resp.HTTPResponse, resp.Body)
 	}
 
-	fmt.Printf("Deleted catalog %s\n", catalogName)
-	return nil
+	return printResult(actionResult("catalog", catalogName, "deleted", fmt.Sprintf("Deleted catalog %s", catalogName)))
 }
 
 func parseCatalogType(input string) (managementapi.CatalogType, error) {
@@ -225,7 +560,7 @@ func parseCatalogType(input string) (managementapi.CatalogType, error) {
 	case "EXTERNAL":
 		return managementapi.EXTERNAL, nil
 	default:
-		return "", fmt.Errorf("invalid catalog type %q (expected INTERNAL or EXTERNAL)", input)
+		return "", usageErrorf("invalid catalog type %q (expected INTERNAL or EXTERNAL)", input)
 	}
 }
 
@@ -240,6 +575,6 @@ func parseStorageType(input string) (managementapi.StorageConfigInfoStorageType,
 	case "FILE":
 		return managementapi.FILE, nil
 	default:
-		return "", fmt.Errorf("invalid storage type %q (expected S3, GCS, AZURE, FILE)", input)
+		return "", usageErrorf("invalid storage type %q (expected S3, GCS, AZURE, FILE)", input)
 	}
 }
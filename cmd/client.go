@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/goravaa/apache-polaris-cli/pkg/api"
+	"github.com/goravaa/apache-polaris-cli/pkg/config"
+)
+
+var logOutput io.Writer
+
+// clientOptions returns the factory options derived from global flags.
+func clientOptions() ([]api.FactoryOption, error) {
+	var opts []api.FactoryOption
+
+	level := api.LogOff
+	if globalVerbose {
+		level = api.LogVerbose
+	}
+	if globalDebug {
+		level = api.LogDebug
+	}
+	if level != api.LogOff {
+		w, err := openLogOutput()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, api.WithLogger(w, level))
+	}
+
+	return opts, nil
+}
+
+// openLogOutput returns the --log-file, opened once for appending, or
+// stderr.
+func openLogOutput() (io.Writer, error) {
+	if logOutput != nil {
+		return logOutput, nil
+	}
+	if globalLogFile == "" {
+		logOutput = os.Stderr
+		return logOutput, nil
+	}
+
+	f, err := os.OpenFile(globalLogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	logOutput = f
+	return logOutput, nil
+}
+
+func newClientFactory(opts ...api.FactoryOption) (*api.Factory, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	return newClientFactoryWithConfig(cfg, opts...)
+}
+
+func newClientFactoryWithConfig(cfg *config.Config, opts ...api.FactoryOption) (*api.Factory, error) {
+	defaults, err := clientOptions()
+	if err != nil {
+		return nil, err
+	}
+	return api.NewFactory(cfg, append(defaults, opts...)...)
+}
+
+func newAuthClient(cfg *config.Config) (*api.AuthClient, error) {
+	factory, err := newClientFactoryWithConfig(cfg, api.WithoutAuth())
+	if err != nil {
+		return nil, err
+	}
+	return factory.Auth(), nil
+}
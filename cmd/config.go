@@ -2,8 +2,16 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+	"time"
 
 	"github.com/goravaa/apache-polaris-cli/pkg/config"
+	"github.com/goravaa/apache-polaris-cli/pkg/output"
 	"github.com/spf13/cobra"
 )
 
@@ -11,6 +19,21 @@ var (
 	configHost          string
 	configRealm         string
 	configCatalogPrefix string
+	configWarehouse     string
+	configCredentials   string
+	configTimeout       string
+	configReqTimeout    string
+	configMaxAttempts   int
+	configRetryDeadline string
+	configStore         string
+	configOIDC          config.OIDCConfig
+	configCredProcess   string
+	configCACert        string
+	configClientCert    string
+	configClientKey     string
+	configInsecure      bool
+	configProxy         string
+	configShowResolved  bool
 )
 
 var configCmd = &cobra.Command{
@@ -26,29 +49,91 @@ var configSetCmd = &cobra.Command{
 
 Examples:
   polaris config set --host http://localhost:8181
-  polaris config set --host https://polaris.example.com --realm my-realm`,
+  polaris config set --host https://polaris.example.com --realm my-realm
+  polaris config set --profile prod --host https://polaris.prod.example.com`,
 	RunE: runConfigSet,
 }
 
 var configShowCmd = &cobra.Command{
 	Use:   "show",
 	Short: "Show current configuration",
-	Long:  `Display the current CLI configuration.`,
-	RunE:  runConfigShow,
+	Long: `Display the current CLI configuration.
+
+With --resolved, show the effective value of every setting after applying
+global flags and POLARIS_* environment variables, and where each value came
+from. Precedence is: flag > environment variable > profile > default.`,
+	RunE: runConfigShow,
+}
+
+var configUseContextCmd = &cobra.Command{
+	Use:   "use-context <profile>",
+	Short: "Switch the current profile",
+	Long: `Make the named profile the default for subsequent commands.
+
+A single command can use a different profile with the global --profile flag.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigUseContext,
+}
+
+var configGetContextsCmd = &cobra.Command{
+	Use:   "get-contexts",
+	Short: "List configured profiles",
+	RunE:  runConfigGetContexts,
+}
+
+var configDeleteContextCmd = &cobra.Command{
+	Use:   "delete-context <profile>",
+	Short: "Delete a profile and its stored credentials",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConfigDeleteContext,
 }
 
 func init() {
 	rootCmd.AddCommand(configCmd)
 	configCmd.AddCommand(configSetCmd)
 	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configUseContextCmd)
+	configCmd.AddCommand(configGetContextsCmd)
+	configCmd.AddCommand(configDeleteContextCmd)
 
 	configSetCmd.Flags().StringVar(&configHost, "host", "", "Polaris server URL (e.g., http://localhost:8181)")
 	configSetCmd.Flags().StringVar(&configRealm, "realm", "", "Polaris realm (for multi-tenant setups)")
 	configSetCmd.Flags().StringVar(&configCatalogPrefix, "catalog-prefix", "", "Default catalog prefix for catalog API calls")
+	configSetCmd.Flags().StringVar(&configWarehouse, "warehouse", "", "Default warehouse; the catalog prefix is then taken from the server's catalog config")
+	configSetCmd.Flags().StringVar(&configTimeout, "timeout", "", "Time limit for a whole command for this profile (e.g., 5m)")
+	configSetCmd.Flags().StringVar(&configReqTimeout, "request-timeout", "", "Timeout for each HTTP request for this profile (e.g., 30s, 2m)")
+	configSetCmd.Flags().IntVar(&configMaxAttempts, "max-attempts", 0, "Attempts per request on transient errors, including the first (0 for the default)")
+	configSetCmd.Flags().StringVar(&configRetryDeadline, "retry-deadline", "", "Total time allowed for a request including retries (e.g., 2m, 0 for no limit)")
+	configSetCmd.Flags().StringVar(&configCredentials, "credentials-file", "", "Credentials file for this profile (relative paths are resolved against ~/.polaris-cli)")
+	configSetCmd.Flags().StringVar(&configStore, "credential-store", "", "Where to keep credentials: keyring (default), encrypted-file or plaintext")
+	configSetCmd.Flags().StringVar(&configCredProcess, "credential-process", "", "Command that prints {\"client_id\", \"client_secret\"} JSON for 'auth login'")
+	configSetCmd.Flags().StringVar(&configCACert, "ca-cert", "", "PEM CA bundle used to verify the server certificate")
+	configSetCmd.Flags().StringVar(&configClientCert, "client-cert", "", "PEM client certificate for mutual TLS")
+	configSetCmd.Flags().StringVar(&configClientKey, "client-key", "", "PEM private key for --client-cert")
+	configSetCmd.Flags().BoolVar(&configInsecure, "insecure-skip-verify", false, "Disable TLS certificate verification (testing only)")
+	configSetCmd.Flags().StringVar(&configProxy, "proxy", "", "HTTP(S) proxy URL (default: HTTPS_PROXY/HTTP_PROXY from the environment)")
+	configSetCmd.Flags().StringVar(&configOIDC.Issuer, "oidc-issuer", "", "OIDC issuer URL for 'auth login --oidc'")
+	configSetCmd.Flags().StringVar(&configOIDC.ClientID, "oidc-client-id", "", "OIDC client ID for 'auth login --oidc'")
+	configSetCmd.Flags().StringVar(&configOIDC.Audience, "oidc-audience", "", "OIDC audience to request")
+	configSetCmd.Flags().StringVar(&configOIDC.Scopes, "oidc-scopes", "", "Space-separated OIDC scopes (default \"openid offline_access\")")
+	configSetCmd.Flags().IntVar(&configOIDC.RedirectPort, "oidc-redirect-port", 0, "Loopback port for the browser login redirect (default: random)")
+
+	configShowCmd.Flags().BoolVar(&configShowResolved, "resolved", false, "Show effective values and their sources")
+
+	setColumns(configSetCmd, profileHeaders)
+	resultColumns[configShowCmd] = func() []string {
+		if configShowResolved {
+			return output.Headers(settingColumns)
+		}
+		return profileHeaders
+	}
+	setColumns(configUseContextCmd, actionHeaders)
+	setColumns(configGetContextsCmd, output.Headers(contextColumns))
+	setColumns(configDeleteContextCmd, actionHeaders)
 }
 
 func runConfigSet(cmd *cobra.Command, args []string) error {
-	cfg, err := config.LoadConfig()
+	cfg, err := config.LoadProfile()
 	if err != nil {
 		cfg = &config.Config{}
 	}
@@ -62,45 +147,442 @@ func runConfigSet(cmd *cobra.Command, args []string) error {
 	if cmd.Flags().Changed("catalog-prefix") {
 		cfg.CatalogPrefix = configCatalogPrefix
 	}
+	if cmd.Flags().Changed("warehouse") {
+		cfg.Warehouse = configWarehouse
+	}
+	if cmd.Flags().Changed("timeout") {
+		if configTimeout != "" {
+			if _, err := time.ParseDuration(configTimeout); err != nil {
+				return usageErrorf("invalid timeout %q: %w", configTimeout, err)
+			}
+		}
+		cfg.Timeout = configTimeout
+	}
+	if cmd.Flags().Changed("request-timeout") {
+		if configReqTimeout != "" {
+			if _, err := time.ParseDuration(configReqTimeout); err != nil {
+				return usageErrorf("invalid request timeout %q: %w", configReqTimeout, err)
+			}
+		}
+		cfg.RequestTimeout = configReqTimeout
+	}
+	if cmd.Flags().Changed("max-attempts") {
+		if configMaxAttempts < 0 {
+			return usageErrorf("invalid max attempts %d: must not be negative", configMaxAttempts)
+		}
+		cfg.MaxAttempts = configMaxAttempts
+	}
+	if cmd.Flags().Changed("retry-deadline") {
+		if configRetryDeadline != "" {
+			if _, err := time.ParseDuration(configRetryDeadline); err != nil {
+				return usageErrorf("invalid retry deadline %q: %w", configRetryDeadline, err)
+			}
+		}
+		cfg.RetryDeadline = configRetryDeadline
+	}
+	if cmd.Flags().Changed("credential-store") {
+		if configStore != "" {
+			if err := config.ValidCredentialStore(configStore); err != nil {
+				return err
+			}
+		}
+		cfg.CredentialStore = configStore
+	}
+	if cmd.Flags().Changed("credential-process") {
+		cfg.CredentialProcess = configCredProcess
+	}
+	if err := applyTLSFlags(cmd, cfg); err != nil {
+		return err
+	}
+	if err := applyOIDCFlags(cmd, cfg); err != nil {
+		return err
+	}
+	if cmd.Flags().Changed("credentials-file") {
+		cfg.CredentialsFile = configCredentials
+	}
 
 	if cfg.Host == "" {
-		return fmt.Errorf("host is required. Use --host to set the Polaris server URL")
+		return usageErrorf("host is required. Use --host to set the Polaris server URL")
 	}
 
 	if err := config.SaveConfig(cfg); err != nil {
 		return fmt.Errorf("failed to save config: %w", err)
 	}
 
-	fmt.Println("✓ Configuration saved!")
-	fmt.Printf("  Host: %s\n", cfg.Host)
-	if cfg.Realm != "" {
-		fmt.Printf("  Realm: %s\n", cfg.Realm)
+	return printResult(profileResult(cfg, func(w io.Writer) {
+		fmt.Fprintln(w, "✓ Configuration saved!")
+		fmt.Fprintf(w, "  Profile: %s\n", cfg.Name)
+		fmt.Fprintf(w, "  Host: %s\n", cfg.Host)
+		if cfg.Realm != "" {
+			fmt.Fprintf(w, "  Realm: %s\n", cfg.Realm)
+		}
+		if cfg.CatalogPrefix != "" {
+			fmt.Fprintf(w, "  Catalog Prefix: %s\n", cfg.CatalogPrefix)
+		}
+		if cfg.Warehouse != "" {
+			fmt.Fprintf(w, "  Warehouse: %s\n", cfg.Warehouse)
+		}
+		if cfg.Timeout != "" {
+			fmt.Fprintf(w, "  Timeout: %s\n", cfg.Timeout)
+		}
+		if cfg.RequestTimeout != "" {
+			fmt.Fprintf(w, "  Request Timeout: %s\n", cfg.RequestTimeout)
+		}
+		if cfg.MaxAttempts > 0 {
+			fmt.Fprintf(w, "  Max Attempts: %d\n", cfg.MaxAttempts)
+		}
+		if cfg.RetryDeadline != "" {
+			fmt.Fprintf(w, "  Retry Deadline: %s\n", cfg.RetryDeadline)
+		}
+		if cfg.CredentialStore != "" {
+			fmt.Fprintf(w, "  Credential Store: %s\n", cfg.CredentialStore)
+		}
+		if cfg.CredentialStore == config.StorePlaintext {
+			fmt.Fprintln(w, "  Warning: credentials for this profile will be stored unencrypted")
+		}
+		if cfg.CredentialProcess != "" {
+			fmt.Fprintf(w, "  Credential Process: %s\n", cfg.CredentialProcess)
+		}
+		if cfg.OIDC != nil {
+			fmt.Fprintf(w, "  OIDC Issuer: %s\n", cfg.OIDC.Issuer)
+			fmt.Fprintf(w, "  OIDC Client ID: %s\n", cfg.OIDC.ClientID)
+		}
+		writeTLSSettings(w, cfg)
+	}))
+}
+
+func applyTLSFlags(cmd *cobra.Command, cfg *config.Config) error {
+	flags := cmd.Flags()
+	for _, f := range []struct {
+		name  string
+		value string
+		dest  *string
+	}{
+		{"ca-cert", configCACert, &cfg.CACertFile},
+		{"client-cert", configClientCert, &cfg.ClientCertFile},
+		{"client-key", configClientKey, &cfg.ClientKeyFile},
+	} {
+		if !flags.Changed(f.name) {
+			continue
+		}
+		path := f.value
+		if path != "" {
+			abs, err := filepath.Abs(path)
+			if err != nil {
+				return usageErrorf("invalid --%s path: %w", f.name, err)
+			}
+			if _, err := os.Stat(abs); err != nil {
+				return usageErrorf("invalid --%s: %w", f.name, err)
+			}
+			path = abs
+		}
+		*f.dest = path
+	}
+
+	if (cfg.ClientCertFile == "") != (cfg.ClientKeyFile == "") {
+		return usageErrorf("--client-cert and --client-key must be set together")
+	}
+
+	if flags.Changed("insecure-skip-verify") {
+		cfg.InsecureSkipVerify = configInsecure
 	}
-	if cfg.CatalogPrefix != "" {
-		fmt.Printf("  Catalog Prefix: %s\n", cfg.CatalogPrefix)
+	if flags.Changed("proxy") {
+		if configProxy != "" {
+			u, err := url.Parse(configProxy)
+			if err != nil || u.Scheme == "" || u.Host == "" {
+				return usageErrorf("invalid --proxy %q (expected a URL such as http://proxy.example.com:3128)", configProxy)
+			}
+		}
+		cfg.Proxy = configProxy
 	}
 
 	return nil
 }
 
+func writeTLSSettings(w io.Writer, cfg *config.Config) {
+	if cfg.CACertFile != "" {
+		fmt.Fprintf(w, "  CA Bundle: %s\n", cfg.CACertFile)
+	}
+	if cfg.ClientCertFile != "" {
+		fmt.Fprintf(w, "  Client Certificate: %s\n", cfg.ClientCertFile)
+		fmt.Fprintf(w, "  Client Key: %s\n", cfg.ClientKeyFile)
+	}
+	if cfg.Proxy != "" {
+		fmt.Fprintf(w, "  Proxy: %s\n", cfg.Proxy)
+	}
+	if cfg.InsecureSkipVerify {
+		fmt.Fprintln(w, "  Insecure Skip Verify: true")
+		fmt.Fprintln(w, "  WARNING: TLS certificate verification is disabled for this profile")
+	}
+}
+
+func applyOIDCFlags(cmd *cobra.Command, cfg *config.Config) error {
+	flags := cmd.Flags()
+	if !flags.Changed("oidc-issuer") && !flags.Changed("oidc-client-id") && !flags.Changed("oidc-audience") &&
+		!flags.Changed("oidc-scopes") && !flags.Changed("oidc-redirect-port") {
+		return nil
+	}
+
+	oidc := config.OIDCConfig{}
+	if cfg.OIDC != nil {
+		oidc = *cfg.OIDC
+	}
+	if flags.Changed("oidc-issuer") {
+		oidc.Issuer = configOIDC.Issuer
+	}
+	if flags.Changed("oidc-client-id") {
+		oidc.ClientID = configOIDC.ClientID
+	}
+	if flags.Changed("oidc-audience") {
+		oidc.Audience = configOIDC.Audience
+	}
+	if flags.Changed("oidc-scopes") {
+		oidc.Scopes = configOIDC.Scopes
+	}
+	if flags.Changed("oidc-redirect-port") {
+		oidc.RedirectPort = configOIDC.RedirectPort
+	}
+
+	if oidc == (config.OIDCConfig{}) {
+		cfg.OIDC = nil
+		return nil
+	}
+	if oidc.Issuer == "" || oidc.ClientID == "" {
+		return fmt.Errorf("both --oidc-issuer and --oidc-client-id are required to enable OIDC login")
+	}
+	cfg.OIDC = &oidc
+	return nil
+}
+
 func runConfigShow(cmd *cobra.Command, args []string) error {
+	if configShowResolved {
+		return runConfigShowResolved()
+	}
+
+	cfg, err := config.LoadProfile()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	return printResult(profileResult(cfg, func(w io.Writer) {
+		fmt.Fprintln(w, "Current Configuration:")
+		fmt.Fprintf(w, "  Profile: %s\n", cfg.Name)
+		fmt.Fprintf(w, "  Host: %s\n", cfg.Host)
+		if cfg.Realm != "" {
+			fmt.Fprintf(w, "  Realm: %s\n", cfg.Realm)
+		} else {
+			fmt.Fprintln(w, "  Realm: (not set)")
+		}
+		if cfg.CatalogPrefix != "" {
+			fmt.Fprintf(w, "  Catalog Prefix: %s\n", cfg.CatalogPrefix)
+		} else {
+			fmt.Fprintln(w, "  Catalog Prefix: (not set)")
+		}
+		if cfg.Warehouse != "" {
+			fmt.Fprintf(w, "  Warehouse: %s\n", cfg.Warehouse)
+		}
+		if cfg.Timeout != "" {
+			fmt.Fprintf(w, "  Timeout: %s\n", cfg.Timeout)
+		}
+		if cfg.RequestTimeout != "" {
+			fmt.Fprintf(w, "  Request Timeout: %s\n", cfg.RequestTimeout)
+		}
+		if cfg.MaxAttempts > 0 {
+			fmt.Fprintf(w, "  Max Attempts: %d\n", cfg.MaxAttempts)
+		}
+		if cfg.RetryDeadline != "" {
+			fmt.Fprintf(w, "  Retry Deadline: %s\n", cfg.RetryDeadline)
+		}
+		if cfg.CredentialStore != "" {
+			fmt.Fprintf(w, "  Credential Store: %s\n", cfg.CredentialStore)
+		} else {
+			fmt.Fprintf(w, "  Credential Store: %s (default)\n", config.DefaultCredentialStore)
+		}
+		if cfg.CredentialsFile != "" {
+			fmt.Fprintf(w, "  Credentials File: %s\n", cfg.CredentialsFile)
+		}
+		if cfg.CredentialProcess != "" {
+			fmt.Fprintf(w, "  Credential Process: %s\n", cfg.CredentialProcess)
+		}
+		if cfg.OIDC != nil {
+			fmt.Fprintf(w, "  OIDC Issuer: %s\n", cfg.OIDC.Issuer)
+			fmt.Fprintf(w, "  OIDC Client ID: %s\n", cfg.OIDC.ClientID)
+			if cfg.OIDC.Audience != "" {
+				fmt.Fprintf(w, "  OIDC Audience: %s\n", cfg.OIDC.Audience)
+			}
+		}
+		writeTLSSettings(w, cfg)
+	}))
+}
+
+var profileHeaders = []string{"NAME", "HOST", "REALM", "CATALOG PREFIX", "WAREHOUSE"}
+
+type profileObject struct {
+	Profile string `json:"profile"`
+	*config.Config
+}
+
+// profileResult shows a profile's stored settings. Its json form is the
+// profile as saved in config.json, with its name added.
+func profileResult(cfg *config.Config, text func(w io.Writer)) *output.Result {
+	return &output.Result{
+		Object:  profileObject{Profile: cfg.Name, Config: cfg},
+		Headers: profileHeaders,
+		Rows:    [][]string{{cfg.Name, cfg.Host, cfg.Realm, cfg.CatalogPrefix, cfg.Warehouse}},
+		Text: func(w io.Writer) error {
+			text(w)
+			return nil
+		},
+	}
+}
+
+func runConfigShowResolved() error {
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	fmt.Println("Current Configuration:")
-	fmt.Printf("  Host: %s\n", cfg.Host)
-	if cfg.Realm != "" {
-		fmt.Printf("  Realm: %s\n", cfg.Realm)
-	} else {
-		fmt.Println("  Realm: (not set)")
+	token, tokenSource, err := config.ResolveToken()
+	if err != nil {
+		token = ""
 	}
-	if cfg.CatalogPrefix != "" {
-		fmt.Printf("  Catalog Prefix: %s\n", cfg.CatalogPrefix)
-	} else {
-		fmt.Println("  Catalog Prefix: (not set)")
+
+	var settings []resolvedSetting
+	row := func(name, value, source string) {
+		settings = append(settings, resolvedSetting{Setting: name, Value: value, Source: source})
 	}
+	row(config.SettingHost, cfg.Host, cfg.Sources[config.SettingHost])
+	row(config.SettingRealm, cfg.Realm, cfg.Sources[config.SettingRealm])
+	row(config.SettingCatalogPrefix, cfg.CatalogPrefix, cfg.Sources[config.SettingCatalogPrefix])
+	row(config.SettingWarehouse, cfg.Warehouse, cfg.Sources[config.SettingWarehouse])
+	row(config.SettingTimeout, cfg.Timeout, cfg.Sources[config.SettingTimeout])
+	row(config.SettingRequestTimeout, cfg.RequestTimeout, cfg.Sources[config.SettingRequestTimeout])
+	row(config.SettingMaxAttempts, strconv.Itoa(cfg.MaxAttempts), cfg.Sources[config.SettingMaxAttempts])
+	row(config.SettingRetryDeadline, cfg.RetryDeadline, cfg.Sources[config.SettingRetryDeadline])
+	row(config.SettingToken, maskToken(token), tokenSource)
 
-	return nil
+	result := &output.Result{
+		Object: resolvedConfig{Profile: cfg.Name, Settings: settings},
+		Text: func(w io.Writer) error {
+			fmt.Fprintf(w, "Resolved Configuration (profile %s):\n", cfg.Name)
+			tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "  SETTING\tVALUE\tSOURCE")
+			for _, s := range settings {
+				value, source := s.Value, s.Source
+				if value == "" {
+					value = "(not set)"
+				}
+				if source == "" {
+					source = "-"
+				}
+				fmt.Fprintf(tw, "  %s\t%s\t%s\n", s.Setting, value, source)
+			}
+			return tw.Flush()
+		},
+	}
+	output.Rows(result, settings, settingColumns)
+	return printResult(result)
+}
+
+var settingColumns = []output.Column[resolvedSetting]{
+	{Header: "SETTING", Value: func(s resolvedSetting) string { return s.Setting }},
+	{Header: "VALUE", Value: func(s resolvedSetting) string { return s.Value }},
+	{Header: "SOURCE", Value: func(s resolvedSetting) string { return s.Source }},
+}
+
+type resolvedSetting struct {
+	Setting string `json:"setting"`
+	Value   string `json:"value"`
+	Source  string `json:"source"`
+}
+
+type resolvedConfig struct {
+	Profile  string            `json:"profile"`
+	Settings []resolvedSetting `json:"settings"`
+}
+
+func maskToken(token string) string {
+	if len(token) > 20 {
+		return token[:10] + "..." + token[len(token)-5:]
+	}
+	if token != "" {
+		return "****"
+	}
+	return ""
+}
+
+func runConfigUseContext(cmd *cobra.Command, args []string) error {
+	if err := config.UseProfile(args[0]); err != nil {
+		return err
+	}
+
+	return printResult(actionResult("profile", args[0], "switched", fmt.Sprintf("✓ Switched to profile %q", args[0])))
+}
+
+func runConfigGetContexts(cmd *cobra.Command, args []string) error {
+	file, err := config.LoadFile()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	active := config.ActiveProfile(file)
+	contexts := []profileContext{}
+	for _, name := range file.ProfileNames() {
+		p := file.Profiles[name]
+		contexts = append(contexts, profileContext{
+			Name:          name,
+			Current:       name == active,
+			Host:          p.Host,
+			Realm:         p.Realm,
+			CatalogPrefix: p.CatalogPrefix,
+			Warehouse:     p.Warehouse,
+			Store:         p.CredentialStore,
+		})
+	}
+
+	result := &output.Result{Object: contexts, Empty: "(no profiles)"}
+	output.Rows(result, contexts, contextColumns)
+	result.Names = make([]string, len(contexts))
+	for i, c := range contexts {
+		result.Names[i] = c.Name
+	}
+	return printResult(result)
+}
+
+var contextColumns = []output.Column[profileContext]{
+	{Header: "CURRENT", Value: func(c profileContext) string {
+		if c.Current {
+			return "*"
+		}
+		return ""
+	}},
+	{Header: "NAME", Value: func(c profileContext) string { return c.Name }},
+	{Header: "HOST", Value: func(c profileContext) string { return c.Host }},
+	{Header: "REALM", Value: func(c profileContext) string { return c.Realm }},
+	{Header: "CATALOG PREFIX", Value: func(c profileContext) string { return c.CatalogPrefix }},
+	{Header: "WAREHOUSE", Wide: true, Value: func(c profileContext) string { return c.Warehouse }},
+	{Header: "CREDENTIAL STORE", Wide: true, Value: func(c profileContext) string { return c.Store }},
+}
+
+type profileContext struct {
+	Name          string `json:"name"`
+	Current       bool   `json:"current"`
+	Host          string `json:"host"`
+	Realm         string `json:"realm,omitempty"`
+	CatalogPrefix string `json:"catalog_prefix,omitempty"`
+	Warehouse     string `json:"warehouse,omitempty"`
+	Store         string `json:"credential_store,omitempty"`
+}
+
+func runConfigDeleteContext(cmd *cobra.Command, args []string) error {
+	switched, err := config.DeleteProfile(args[0])
+	if err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("✓ Deleted profile %q", args[0])
+	if switched != "" {
+		message += fmt.Sprintf("\n✓ Switched to profile %q", switched)
+	}
+	return printResult(actionResult("profile", args[0], "deleted", message))
 }
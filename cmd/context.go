@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/goravaa/apache-polaris-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// limitCommands bounds every command by the resolved --timeout, which
+// covers all requests, retries and token renewals the command makes.
+func limitCommands(root *cobra.Command) {
+	wrapRunE(root, func(run runE) runE {
+		return func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.LoadConfig()
+			if err != nil || cfg.CommandTimeout() <= 0 {
+				return run(cmd, args)
+			}
+
+			timeout := cfg.CommandTimeout()
+			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+			defer cancel()
+			cmd.SetContext(ctx)
+
+			err = run(cmd, args)
+			if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return fmt.Errorf("command timed out after %s: %w", timeout, err)
+			}
+			return err
+		}
+	})
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/goravaa/apache-polaris-cli/pkg/api"
+	"github.com/goravaa/apache-polaris-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes are part of the CLI's public interface so that scripts can
+// branch on the kind of failure. Do not renumber them.
+const (
+	ExitOK               = 0
+	ExitError            = 1   // any failure not covered below
+	ExitUsage            = 2   // invalid command line: unknown command or flag, bad arguments
+	ExitNotFound         = 3   // catalog, namespace, table, view or other resource does not exist
+	ExitConflict         = 4   // resource already exists or a commit conflicted
+	ExitAuth             = 5   // not logged in, or credentials expired or rejected
+	ExitPermissionDenied = 6   // authenticated but not allowed
+	ExitValidation       = 7   // the server rejected the request as invalid or unsupported
+	ExitTransient        = 8   // network failure, timeout or server error; retrying may help
+	ExitInterrupted      = 130 // cancelled by SIGINT or SIGTERM
+)
+
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+
+func (e *exitError) Unwrap() error { return e.err }
+
+func withExitCode(code int, err error) error {
+	return &exitError{code: code, err: err}
+}
+
+// usageErrorf reports an invalid command line, such as a missing or
+// malformed flag value.
+func usageErrorf(format string, a ...any) error {
+	return withExitCode(ExitUsage, fmt.Errorf(format, a...))
+}
+
+func exitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+
+	var (
+		noSuchNamespace *api.NoSuchNamespaceError
+		noSuchTable     *api.NoSuchTableError
+		noSuchView      *api.NoSuchViewError
+		notFound        *api.NotFoundError
+		alreadyExists   *api.AlreadyExistsError
+		commitFailed    *api.CommitFailedError
+		forbidden       *api.ForbiddenError
+		badRequest      *api.BadRequestError
+		unsupported     *api.UnsupportedError
+		notSupported    *api.EndpointNotSupportedError
+		serverErr       *api.ServerError
+		commitUnknown   *api.CommitStateUnknownError
+		opErr           *net.OpError
+	)
+	switch {
+	case errors.Is(err, context.Canceled):
+		return ExitInterrupted
+	case errors.Is(err, config.ErrInvalidProfileName):
+		return ExitUsage
+	case errors.Is(err, config.ErrKeyringUnavailable):
+		// A local setup problem: logging in again would not help.
+		return ExitError
+	case errors.Is(err, api.ErrUnauthorized), errors.Is(err, config.ErrNotAuthenticated):
+		return ExitAuth
+	case errors.As(err, &noSuchNamespace), errors.As(err, &noSuchTable), errors.As(err, &noSuchView), errors.As(err, &notFound):
+		return ExitNotFound
+	case errors.As(err, &alreadyExists), errors.As(err, &commitFailed):
+		return ExitConflict
+	case errors.As(err, &forbidden):
+		return ExitPermissionDenied
+	case errors.As(err, &badRequest), errors.As(err, &unsupported), errors.As(err, &notSupported):
+		return ExitValidation
+	case errors.As(err, &serverErr), errors.As(err, &commitUnknown), errors.As(err, &opErr),
+		errors.Is(err, context.DeadlineExceeded), errors.Is(err, io.ErrUnexpectedEOF):
+		return ExitTransient
+	default:
+		return ExitError
+	}
+}
+
+// exitCodes makes errors that cobra returns before a command starts
+// running, such as unknown commands, bad arguments or missing required
+// flags, exit with ExitUsage. It returns the function that maps an error
+// from root.Execute to the process exit code.
+func exitCodes(root *cobra.Command) func(error) int {
+	started := false
+
+	root.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return withExitCode(ExitUsage, err)
+	})
+
+	wrapRunE(root, func(run runE) runE {
+		return func(cmd *cobra.Command, args []string) error {
+			started = true
+			return run(cmd, args)
+		}
+	})
+	wrapArgs(root, func(validate cobra.PositionalArgs) cobra.PositionalArgs {
+		return func(cmd *cobra.Command, args []string) error {
+			if err := validate(cmd, args); err != nil {
+				return withExitCode(ExitUsage, err)
+			}
+			return nil
+		}
+	})
+
+	return func(err error) int {
+		var exitErr *exitError
+		if !started && err != nil && !errors.As(err, &exitErr) {
+			return ExitUsage
+		}
+		return exitCode(err)
+	}
+}
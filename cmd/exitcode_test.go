@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/goravaa/apache-polaris-cli/pkg/api"
+	"github.com/goravaa/apache-polaris-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// apiError decodes a server response the way commands do.
+func apiError(status int, errorType string) error {
+	body := fmt.Sprintf(`{"error":{"message":"failed","type":%q,"code":%d}}`, errorType, status)
+	return api.ResponseError(&http.Response{StatusCode: status, Status: http.StatusText(status)}, []byte(body))
+}
+
+func TestExitCode(t *testing.T) {
+	connRefused := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"success", nil, ExitOK},
+		{"plain error", errors.New("boom"), ExitError},
+		{"keyring unavailable", fmt.Errorf("load: %w", config.ErrKeyringUnavailable), ExitError},
+
+		{"usage error", usageErrorf("--name is required"), ExitUsage},
+		{"explicit code", withExitCode(ExitValidation, errors.New("bad")), ExitValidation},
+		{"invalid profile name", fmt.Errorf("failed to save config: %w", config.ErrInvalidProfileName), ExitUsage},
+
+		{"unauthorized", fmt.Errorf("request failed: %w", api.ErrUnauthorized), ExitAuth},
+		{"401 response", apiError(http.StatusUnauthorized, "NotAuthorizedException"), ExitAuth},
+		{"not logged in", fmt.Errorf("not authenticated: %w", config.ErrNotAuthenticated), ExitAuth},
+
+		{"no such namespace", apiError(http.StatusNotFound, "NoSuchNamespaceException"), ExitNotFound},
+		{"no such table", apiError(http.StatusNotFound, "NoSuchTableException"), ExitNotFound},
+		{"no such view", apiError(http.StatusNotFound, "NoSuchViewException"), ExitNotFound},
+		{"catalog not found", apiError(http.StatusNotFound, "NotFoundException"), ExitNotFound},
+		{"untyped 404", apiError(http.StatusNotFound, ""), ExitNotFound},
+
+		{"already exists", apiError(http.StatusConflict, "AlreadyExistsException"), ExitConflict},
+		{"commit failed", apiError(http.StatusConflict, "CommitFailedException"), ExitConflict},
+		{"wrapped commit failed", fmt.Errorf("catalog changed: %w", apiError(http.StatusConflict, "CommitFailedException")), ExitConflict},
+		{"untyped 409", apiError(http.StatusConflict, ""), ExitConflict},
+
+		{"forbidden", apiError(http.StatusForbidden, "ForbiddenException"), ExitPermissionDenied},
+
+		{"bad request", apiError(http.StatusBadRequest, "BadRequestException"), ExitValidation},
+		{"unsupported", apiError(http.StatusNotAcceptable, "UnsupportedOperationException"), ExitValidation},
+		{"endpoint not advertised", &api.EndpointNotSupportedError{Endpoint: api.EndpointListViews}, ExitValidation},
+
+		{"server error", apiError(http.StatusServiceUnavailable, "ServiceUnavailableException"), ExitTransient},
+		{"commit state unknown", apiError(http.StatusInternalServerError, "CommitStateUnknownException"), ExitTransient},
+		{"connection refused", fmt.Errorf("failed to connect: %w", connRefused), ExitTransient},
+		{"deadline exceeded", fmt.Errorf("request: %w", context.DeadlineExceeded), ExitTransient},
+		{"truncated body", io.ErrUnexpectedEOF, ExitTransient},
+
+		{"interrupted", fmt.Errorf("request: %w", context.Canceled), ExitInterrupted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestExitCodeTokenRenewal checks that only a rejected renewal asks for a
+// new login, and that network and server failures while renewing keep their
+// own exit codes.
+func TestExitCodeTokenRenewal(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		offline bool
+		want    int
+	}{
+		{"invalid client", http.StatusUnauthorized, `{"error":"invalid_client","error_description":"bad secret"}`, false, ExitAuth},
+		{"invalid grant", http.StatusBadRequest, `{"error":"invalid_grant"}`, false, ExitAuth},
+		{"bad request without OAuth error", http.StatusBadRequest, `bad request`, false, ExitAuth},
+		{"server error", http.StatusInternalServerError, `{"error":"server_error"}`, false, ExitTransient},
+		{"service unavailable", http.StatusServiceUnavailable, ``, false, ExitTransient},
+		{"rate limited", http.StatusTooManyRequests, ``, false, ExitTransient},
+		{"token endpoint missing", http.StatusNotFound, ``, false, ExitNotFound},
+		{"connection refused", 0, ``, true, ExitTransient},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			if tt.offline {
+				server.Close()
+			} else {
+				t.Cleanup(server.Close)
+			}
+
+			t.Setenv("HOME", t.TempDir())
+			t.Setenv(config.EnvCredentialStore, config.StorePlaintext)
+			t.Setenv(config.EnvProfile, "")
+			if err := config.SaveConfig(&config.Config{Name: config.DefaultProfileName, Host: server.URL, MaxAttempts: 1}); err != nil {
+				t.Fatal(err)
+			}
+			creds := &config.Credentials{AccessToken: "old", ClientID: "id", ClientSecret: "secret", IssuedAt: time.Now(), ExpiresIn: 3600}
+			if err := config.SaveCredentials(creds); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+			tokens, err := api.NewTokenProvider(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = tokens.Renew(context.Background(), "old")
+			if err == nil {
+				t.Fatal("Renew succeeded")
+			}
+			if got := exitCode(err); got != tt.want {
+				t.Errorf("exitCode(%v) = %d, want %d", err, got, tt.want)
+			}
+		})
+	}
+}
+
+// newExitCodeTree builds a small command tree, so that exitCodes can be
+// checked without the global root command.
+func newExitCodeTree(runErr error) *cobra.Command {
+	root := &cobra.Command{Use: "polaris", SilenceErrors: true, SilenceUsage: true}
+	child := &cobra.Command{
+		Use:  "get <name>",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error { return runErr },
+	}
+	child.Flags().String("namespace", "", "")
+	root.AddCommand(child)
+	return root
+}
+
+func TestExitCodesBeforeRun(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		runErr error
+		want   int
+	}{
+		{"unknown command", []string{"nope"}, nil, ExitUsage},
+		{"unknown flag", []string{"get", "t1", "--bogus"}, nil, ExitUsage},
+		{"missing flag value", []string{"get", "t1", "--namespace"}, nil, ExitUsage},
+		{"wrong number of arguments", []string{"get"}, nil, ExitUsage},
+		{"success", []string{"get", "t1"}, nil, ExitOK},
+		{"command error", []string{"get", "t1"}, apiError(http.StatusNotFound, "NoSuchTableException"), ExitNotFound},
+		{"plain command error", []string{"get", "t1"}, errors.New("boom"), ExitError},
+		{"usage error from command", []string{"get", "t1"}, usageErrorf("--namespace is required"), ExitUsage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := newExitCodeTree(tt.runErr)
+			code := exitCodes(root)
+			root.SetArgs(tt.args)
+			root.SetOut(io.Discard)
+			root.SetErr(io.Discard)
+			if got := code(root.Execute()); got != tt.want {
+				t.Errorf("exit code = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
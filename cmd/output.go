@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/goravaa/apache-polaris-cli/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	outputSpec         = output.Spec{Format: output.Table}
+	outputTemplateFile string
+	outputColumns      []string
+	outputSortBy       string
+	outputNoHeaders    bool
+)
+
+// printResult writes the result of a command to stdout in the format chosen
+// with --output. Commands print progress and hints to stderr so that stdout
+// only carries the result.
+func printResult(r *output.Result) error {
+	return outputError(output.Print(os.Stdout, outputSpec, r))
+}
+
+// outputError gives the exit code for a failure to print a result.
+func outputError(err error) error {
+	if errors.Is(err, output.ErrUnknownColumn) {
+		return withExitCode(ExitUsage, err)
+	}
+	return err
+}
+
+// checkOutput reads --template-file and validates the output flags before
+// a command runs, so that a bad template or column is reported before any
+// change is made. A template file alone implies -o go-template.
+func checkOutput(root *cobra.Command) {
+	wrapRunE(root, func(run runE) runE {
+		return func(cmd *cobra.Command, args []string) error {
+			if outputTemplateFile != "" {
+				if outputSpec.Template != "" {
+					return usageErrorf("--template-file cannot be combined with an inline template in --output")
+				}
+				if !outputSpec.Templated() {
+					if cmd.Flags().Changed("output") {
+						return usageErrorf("--template-file requires --output go-template or jsonpath")
+					}
+					outputSpec.Format = output.GoTemplate
+				}
+				data, err := os.ReadFile(outputTemplateFile)
+				if err != nil {
+					return usageErrorf("failed to read template file: %w", err)
+				}
+				outputSpec.Template = string(data)
+			}
+			if err := outputSpec.Validate(); err != nil {
+				return usageErrorf("%w", err)
+			}
+
+			tabular := outputSpec.Format == output.Table || outputSpec.Format == output.Wide || outputSpec.Format == output.CSV
+			if (len(outputColumns) > 0 || outputNoHeaders) && !tabular {
+				return usageErrorf("--columns and --no-headers apply to table, wide and csv output")
+			}
+			if outputSortBy != "" && !tabular && outputSpec.Format != output.Name {
+				return usageErrorf("--sort-by applies to table, wide, csv and name output")
+			}
+			outputSpec.Columns = outputColumns
+			outputSpec.SortBy = outputSortBy
+			outputSpec.NoHeaders = outputNoHeaders
+			if len(outputColumns) > 0 || outputSortBy != "" {
+				headers, ok := resultColumns[cmd]
+				if !ok {
+					return usageErrorf("%s has no columns for --columns or --sort-by", cmd.CommandPath())
+				}
+				if err := outputSpec.CheckColumns(headers()); err != nil {
+					return usageErrorf("%w", err)
+				}
+			}
+			return run(cmd, args)
+		}
+	})
+}
+
+// resultColumns holds the headers of the results each command prints, for
+// checking --columns and --sort-by before the command runs.
+var resultColumns = map[*cobra.Command]func() []string{}
+
+// setColumns records that cmd prints results with these headers.
+func setColumns(cmd *cobra.Command, headers []string) {
+	resultColumns[cmd] = func() []string { return headers }
+}
+
+type actionObject struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Action string `json:"action"`
+}
+
+var actionHeaders = []string{"KIND", "NAME", "ACTION"}
+
+// actionResult reports a change that has no API object to show, such as a
+// deletion.
+func actionResult(kind, name, action, text string) *output.Result {
+	return &output.Result{
+		Object:  actionObject{Kind: kind, Name: name, Action: action},
+		Headers: actionHeaders,
+		Rows:    [][]string{{kind, name, action}},
+		Names:   []string{name},
+		Text: func(w io.Writer) error {
+			_, err := fmt.Fprintln(w, text)
+			return err
+		},
+	}
+}
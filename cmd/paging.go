@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/goravaa/apache-polaris-cli/pkg/api"
+	catalogapi "github.com/goravaa/apache-polaris-cli/pkg/api/openapi/catalog"
+	"github.com/goravaa/apache-polaris-cli/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+type pageFlags struct {
+	size     int
+	token    string
+	all      bool
+	maxItems int
+}
+
+func (f *pageFlags) register(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&f.size, "page-size", 0, "Number of results to request per page")
+	cmd.Flags().StringVar(&f.token, "page-token", "", "Resume from the next-page token printed by an earlier call")
+	cmd.Flags().BoolVar(&f.all, "all", false, "Follow next-page tokens until every result is listed")
+	cmd.Flags().IntVar(&f.maxItems, "max-items", 0, "Stop after this many results")
+	addWatchFlags(cmd)
+}
+
+func (f *pageFlags) options() (api.PageOptions, error) {
+	if f.size < 0 {
+		return api.PageOptions{}, usageErrorf("--page-size must not be negative")
+	}
+	if f.maxItems < 0 {
+		return api.PageOptions{}, usageErrorf("--max-items must not be negative")
+	}
+	return api.PageOptions{
+		Size:     f.size,
+		Token:    f.token,
+		All:      f.all,
+		MaxItems: f.maxItems,
+	}, nil
+}
+
+// listPages lists the requested pages and prints their items with build,
+// which turns them and the next-page token into a result. The table, wide,
+// csv and name formats are printed a page at a time as pages arrive; the
+// other formats write one document, so the items are collected first. When
+// more results are available or the listing fails part way, the items
+// fetched so far are still printed and the user is told how to continue.
+// With --watch the listing is repeated and only changes are printed.
+func listPages[T any](ctx context.Context, flags *pageFlags, fetch api.PageFunc[T], build func(items []T, next string) *output.Result) error {
+	opts, err := flags.options()
+	if err != nil {
+		return err
+	}
+
+	collect := func(ctx context.Context) ([]T, api.Progress, error) {
+		items := []T{}
+		progress, err := api.Paginate(ctx, fetch, opts, func(page []T) error {
+			items = append(items, page...)
+			return nil
+		})
+		return items, progress, err
+	}
+
+	if watchEnabled {
+		return watchResult(ctx, func(ctx context.Context) (*output.Result, error) {
+			items, progress, err := collect(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return build(items, progress.Next), nil
+		})
+	}
+
+	var progress api.Progress
+	if outputSpec.Streams() {
+		stream := output.NewStream(os.Stdout, outputSpec)
+		progress, err = api.Paginate(ctx, fetch, opts, func(page []T) error {
+			return outputError(stream.Write(build(page, "")))
+		})
+		if err == nil {
+			err = stream.Close()
+		}
+	} else {
+		var items []T
+		items, progress, err = collect(ctx)
+		if err == nil || progress.Count > 0 {
+			if perr := printResult(build(items, progress.Next)); perr != nil {
+				return perr
+			}
+		}
+	}
+	if err != nil {
+		if progress.Count > 0 {
+			fmt.Fprintf(os.Stderr, "Listed %d results before stopping.\n", progress.Count)
+			if progress.Next != "" {
+				fmt.Fprintf(os.Stderr, "Resume with --page-token %s\n", progress.Next)
+			}
+		}
+		return err
+	}
+	reportMore(progress)
+	return nil
+}
+
+// reportMore tells the user, on stderr, how to list the results that were
+// left out.
+func reportMore(progress api.Progress) {
+	switch {
+	case progress.Truncated:
+		fmt.Fprintf(os.Stderr, "Stopped after --max-items %d. The server sent more results in the same page, so there is no token to resume from; raise --max-items to list them.\n", progress.Count)
+	case progress.Next != "":
+		fmt.Fprintf(os.Stderr, "More results are available. Use --all, or --page-token %s to continue.\n", progress.Next)
+	}
+}
+
+// nextPageToken returns the next-page-token field of a list response.
+func nextPageToken(next string) *catalogapi.PageToken {
+	if next == "" {
+		return nil
+	}
+	return &next
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/goravaa/apache-polaris-cli/pkg/api"
+	managementapi "github.com/goravaa/apache-polaris-cli/pkg/api/openapi/management"
+	"github.com/goravaa/apache-polaris-cli/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var principalsCmd = &cobra.Command{
+	Use:   "principals",
+	Short: "Principal management commands",
+}
+
+var principalsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List principals",
+	RunE:  runPrincipalsList,
+}
+
+func init() {
+	rootCmd.AddCommand(principalsCmd)
+	principalsCmd.AddCommand(principalsListCmd)
+
+	addWatchFlags(principalsListCmd)
+	setColumns(principalsListCmd, output.Headers(principalColumns))
+}
+
+func runPrincipalsList(cmd *cobra.Command, args []string) error {
+	client, _, err := newManagementClient()
+	if err != nil {
+		return err
+	}
+
+	return showResult(cmd.Context(), func(ctx context.Context) (*output.Result, error) {
+		resp, err := client.ListPrincipalsWithResponse(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if resp.JSON200 == nil {
+			return nil, api.ResponseError(resp.HTTPResponse, resp.Body)
+		}
+
+		result := &output.Result{Object: resp.JSON200, Empty: "(no principals)"}
+		output.Rows(result, resp.JSON200.Principals, principalColumns)
+		return result, nil
+	})
+}
+
+var principalColumns = []output.Column[managementapi.Principal]{
+	{Header: "NAME", Value: func(p managementapi.Principal) string { return p.Name }},
+	{Header: "CLIENT ID", Value: func(p managementapi.Principal) string {
+		if p.ClientId == nil {
+			return ""
+		}
+		return *p.ClientId
+	}},
+	{Header: "UPDATED", Value: func(p managementapi.Principal) string { return formatMillisPtr(p.LastUpdateTimestamp) }},
+	{Header: "CREATED", Wide: true, Value: func(p managementapi.Principal) string { return formatMillisPtr(p.CreateTimestamp) }},
+	{Header: "ENTITY VERSION", Wide: true, Value: func(p managementapi.Principal) string { return formatIntPtr(p.EntityVersion) }},
+}
@@ -1,14 +1,36 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
+	"github.com/goravaa/apache-polaris-cli/pkg/api"
+	"github.com/goravaa/apache-polaris-cli/pkg/config"
 	"github.com/spf13/cobra"
 )
 
 var (
 	Version = "dev"
+
+	profileName   string
+	globalHost    string
+	globalRealm   string
+	globalTimeout time.Duration
+	globalToken   string
+
+	globalRequestTimeout time.Duration
+
+	globalMaxAttempts   int
+	globalRetryDeadline time.Duration
+
+	globalVerbose bool
+	globalDebug   bool
+	globalLogFile string
 )
 
 var rootCmd = &cobra.Command{
@@ -19,17 +41,125 @@ Apache Polaris catalogs, namespaces, tables, and more.
 
 To get started, configure your Polaris server and authenticate:
   polaris config set --host http://localhost:8181
-  polaris auth login --client-id <your-client-id> --client-secret <your-client-secret>`,
+  polaris auth login --client-id <your-client-id> --client-secret <your-client-secret>
+
+Output:
+  --output selects table (default), wide, json, yaml, csv, name,
+  go-template or jsonpath; see 'polaris catalogs list --help' for examples.
+
+Exit codes:
+  0    success
+  1    other error
+  2    usage error (unknown command or flag, invalid arguments)
+  3    not found
+  4    conflict (already exists, commit failed)
+  5    authentication required, expired or rejected
+  6    permission denied
+  7    validation error reported by the server
+  8    network failure, timeout or server error
+  130  interrupted by SIGINT or SIGTERM`,
 	Version: Version,
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+	rootCmd.SilenceUsage = true
+	rootCmd.SilenceErrors = true
+	code := exitCodes(rootCmd)
+	limitCommands(rootCmd)
+	traceCommands(rootCmd)
+	checkOutput(rootCmd)
+
+	// The first SIGINT or SIGTERM cancels the running command so that it
+	// can stop cleanly; a second one terminates the process immediately.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	cmd, err := rootCmd.ExecuteContextC(ctx)
+	interrupted := ctx.Err() != nil
+	stop()
+	flushTracing()
+	if err != nil {
+		if interrupted {
+			err = fmt.Errorf("interrupted: %w", err)
+		}
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		status := code(err)
+		if status == ExitUsage {
+			fmt.Fprintf(os.Stderr, "Run '%s --help' for usage.\n", cmd.CommandPath())
+		}
+		os.Exit(status)
 	}
 }
 
 func init() {
-	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Enable verbose output")
+	cobra.OnInitialize(func() {
+		api.UserAgent = "polaris-cli/" + Version
+		config.SetProfileOverride(profileName)
+
+		overrides := config.Overrides{
+			Host:          globalHost,
+			Realm:         globalRealm,
+			CatalogPrefix: catalogPrefix,
+			Warehouse:     catalogWarehouse,
+			Token:         globalToken,
+		}
+		if globalTimeout > 0 {
+			overrides.Timeout = globalTimeout.String()
+		}
+		if globalRequestTimeout > 0 {
+			overrides.RequestTimeout = globalRequestTimeout.String()
+		}
+		if globalMaxAttempts > 0 {
+			overrides.MaxAttempts = strconv.Itoa(globalMaxAttempts)
+		}
+		if rootCmd.PersistentFlags().Changed("retry-deadline") {
+			overrides.RetryDeadline = globalRetryDeadline.String()
+		}
+		config.SetOverrides(overrides)
+	})
+
+	rootCmd.PersistentFlags().VarP(&outputSpec, "output", "o", "Output format: table, wide, json, yaml, csv, name, go-template=TEMPLATE or jsonpath=EXPRESSION")
+	rootCmd.PersistentFlags().StringSliceVar(&outputColumns, "columns", nil, "Comma-separated columns to show in table, wide or csv output (e.g. name,type)")
+	rootCmd.PersistentFlags().StringVar(&outputSortBy, "sort-by", "", "Sort table, wide, csv or name output by this column")
+	rootCmd.PersistentFlags().BoolVar(&outputNoHeaders, "no-headers", false, "Leave out the header row of table, wide or csv output")
+	rootCmd.PersistentFlags().StringVar(&outputTemplateFile, "template-file", "", "Read the go-template or jsonpath expression for --output from this file")
+	rootCmd.PersistentFlags().BoolVarP(&globalVerbose, "verbose", "v", false, "Log each HTTP request with its status and latency")
+	rootCmd.PersistentFlags().BoolVar(&globalDebug, "debug", false, "Log HTTP headers and bodies as well (credentials are redacted)")
+	rootCmd.PersistentFlags().StringVar(&otelEndpoint, "otel-endpoint", "", "Export OpenTelemetry traces over OTLP/HTTP to this URL (default: OTEL_EXPORTER_OTLP_ENDPOINT)")
+	rootCmd.PersistentFlags().StringVar(&otelFile, "otel-file", "", "Append OpenTelemetry spans as JSON to this file")
+	rootCmd.PersistentFlags().StringVar(&globalLogFile, "log-file", "", "Append the --verbose or --debug log to this file instead of stderr")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "Configuration profile to use (overrides the current context)")
+	rootCmd.PersistentFlags().StringVar(&globalHost, "host", "", "Polaris server URL (overrides POLARIS_HOST and the profile)")
+	rootCmd.PersistentFlags().StringVar(&globalRealm, "realm", "", "Polaris realm (overrides POLARIS_REALM and the profile)")
+	rootCmd.PersistentFlags().DurationVar(&globalTimeout, "timeout", 0, "Time limit for the whole command (overrides POLARIS_TIMEOUT and the profile)")
+	rootCmd.PersistentFlags().DurationVar(&globalRequestTimeout, "request-timeout", 0, "Timeout for each HTTP request (overrides POLARIS_REQUEST_TIMEOUT and the profile, default 30s)")
+	rootCmd.PersistentFlags().IntVar(&globalMaxAttempts, "max-attempts", 0, "Attempts per request on transient errors, including the first (overrides POLARIS_MAX_ATTEMPTS and the profile)")
+	rootCmd.PersistentFlags().DurationVar(&globalRetryDeadline, "retry-deadline", 0, "Total time allowed for a request including retries, 0 for no limit (overrides POLARIS_RETRY_DEADLINE and the profile)")
+	rootCmd.PersistentFlags().StringVar(&globalToken, "token", "", "Bearer token to use instead of stored credentials (overrides POLARIS_TOKEN)")
+}
+
+type runE func(cmd *cobra.Command, args []string) error
+
+// wrapRunE replaces the RunE of every command under root with wrap(RunE).
+func wrapRunE(root *cobra.Command, wrap func(run runE) runE) {
+	if root.RunE != nil {
+		root.RunE = wrap(root.RunE)
+	}
+	for _, child := range root.Commands() {
+		wrapRunE(child, wrap)
+	}
+}
+
+// wrapArgs replaces the Args validator of every command under root that has
+// one with wrap(Args).
+func wrapArgs(root *cobra.Command, wrap func(validate cobra.PositionalArgs) cobra.PositionalArgs) {
+	if root.Args != nil {
+		root.Args = wrap(root.Args)
+	}
+	for _, child := range root.Commands() {
+		wrapArgs(child, wrap)
+	}
 }
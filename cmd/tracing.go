@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/goravaa/apache-polaris-cli/pkg/telemetry"
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const traceFlushTimeout = 5 * time.Second
+
+var (
+	otelEndpoint string
+	otelFile     string
+
+	shutdownTracing = func(context.Context) error { return nil }
+)
+
+// traceCommands runs every command inside a span named after its command
+// path. Tracing is set up on first use so that the global flags have been
+// parsed by then.
+func traceCommands(root *cobra.Command) {
+	wrapRunE(root, func(run runE) runE {
+		return func(cmd *cobra.Command, args []string) error {
+			shutdown, err := telemetry.Setup(cmd.Context(), telemetry.Options{
+				Endpoint: otelEndpoint,
+				File:     otelFile,
+				Version:  Version,
+			})
+			if err != nil {
+				return err
+			}
+			shutdownTracing = shutdown
+
+			ctx, span := telemetry.Tracer().Start(telemetry.ParentContext(cmd.Context()), cmd.CommandPath(),
+				trace.WithAttributes(attribute.String("polaris.profile", profileName)),
+			)
+			defer span.End()
+			cmd.SetContext(ctx)
+
+			err = run(cmd, args)
+			span.SetAttributes(attribute.Int("process.exit.code", exitCode(err)))
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			return err
+		}
+	})
+}
+
+func flushTracing() {
+	ctx, cancel := context.WithTimeout(context.Background(), traceFlushTimeout)
+	defer cancel()
+	if err := shutdownTracing(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to export traces: %v\n", err)
+	}
+}
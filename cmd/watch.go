@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/goravaa/apache-polaris-cli/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+const defaultWatchInterval = 2 * time.Second
+
+var (
+	watchEnabled  bool
+	watchInterval time.Duration
+)
+
+func addWatchFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&watchEnabled, "watch", "w", false, "Keep polling and print additions (+), removals (-) and changes (~); with -o json, print one change event per line")
+	cmd.Flags().DurationVar(&watchInterval, "interval", defaultWatchInterval, "Time between polls with --watch")
+}
+
+// showResult prints the result of load, or with --watch keeps polling it.
+func showResult(ctx context.Context, load func(context.Context) (*output.Result, error)) error {
+	if !watchEnabled {
+		r, err := load(ctx)
+		if err != nil {
+			return err
+		}
+		return printResult(r)
+	}
+	return watchResult(ctx, load)
+}
+
+// watchResult polls load every --interval until interrupted and prints what
+// changed between polls. Transient failures are reported and retried on the
+// next poll, and a watched resource that disappears is reported as removed.
+// Interrupting the watch is a normal way to end it and exits successfully.
+func watchResult(ctx context.Context, load func(context.Context) (*output.Result, error)) error {
+	if watchInterval <= 0 {
+		return usageErrorf("--interval must be positive")
+	}
+	if !slices.Contains(output.WatchFormats, outputSpec.Format) {
+		return usageErrorf("--watch supports table, wide, csv, name and json output")
+	}
+
+	watcher := output.NewWatcher(os.Stdout, outputSpec)
+	var last *output.Result
+	for {
+		r, err := load(ctx)
+		switch {
+		case err == nil:
+		case ctx.Err() != nil:
+			return watchStopped(ctx)
+		case last != nil && exitCode(err) == ExitNotFound:
+			r = &output.Result{Headers: last.Headers, Wide: last.Wide}
+			if last.Text != nil {
+				r.Text = func(io.Writer) error { return nil }
+			}
+		case exitCode(err) == ExitTransient:
+			fmt.Fprintf(os.Stderr, "Warning: %v (retrying in %s)\n", err, watchInterval)
+		default:
+			return err
+		}
+
+		if r != nil {
+			if err := watcher.Update(r); err != nil {
+				return err
+			}
+			last = r
+		}
+
+		select {
+		case <-ctx.Done():
+			return watchStopped(ctx)
+		case <-time.After(watchInterval):
+		}
+	}
+}
+
+func watchStopped(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ctx.Err()
+	}
+	return nil
+}
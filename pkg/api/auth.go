@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -16,6 +17,11 @@ const (
 	TokenEndpoint   = "/api/catalog/v1/oauth/tokens"
 	RealmHeaderName = "Polaris-Realm"
 	DefaultScope    = "PRINCIPAL_ROLE:ALL"
+
+	principalRoleScopePrefix = "PRINCIPAL_ROLE:"
+
+	AuthMethodOIDC              = "oidc"
+	AuthMethodCredentialProcess = "credential_process"
 )
 
 type OAuthTokenResponse struct {
@@ -30,39 +36,60 @@ type OAuthError struct {
 	ErrorDescription string `json:"error_description"`
 }
 
+// tokenError turns an error response from a token endpoint into an error.
+// OAuth error responses and 400 or 401 mean the credentials or grant were
+// rejected. Other responses, such as server errors, keep the type of the
+// status, so that they are not mistaken for a failed login.
+func tokenError(prefix string, status int, body []byte) error {
+	resp := &http.Response{StatusCode: status, Status: fmt.Sprintf("%d %s", status, http.StatusText(status))}
+	if status >= 500 || status == http.StatusTooManyRequests {
+		return fmt.Errorf("%s: %w", prefix, ResponseError(resp, body))
+	}
+	var oauthErr OAuthError
+	if err := json.Unmarshal(body, &oauthErr); err == nil && oauthErr.Error != "" {
+		return unauthorizedf("%s: %s - %s", prefix, oauthErr.Error, oauthErr.ErrorDescription)
+	}
+	if status == http.StatusBadRequest || status == http.StatusUnauthorized {
+		return unauthorizedf("%s with status %d: %s", prefix, status, string(body))
+	}
+	return fmt.Errorf("%s: %w", prefix, ResponseError(resp, body))
+}
+
 type AuthClient struct {
 	httpClient *http.Client
 	config     *config.Config
+	factory    *Factory
 }
 
-func NewAuthClient(cfg *config.Config) *AuthClient {
-	return &AuthClient{
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		config: cfg,
+func NewAuthClient(cfg *config.Config) (*AuthClient, error) {
+	factory, err := NewFactory(cfg, WithoutAuth())
+	if err != nil {
+		return nil, err
 	}
+	return factory.Auth(), nil
 }
 
-func (c *AuthClient) Login(clientID, clientSecret string) (*config.Credentials, error) {
+func (c *AuthClient) Login(ctx context.Context, clientID, clientSecret string) (*config.Credentials, error) {
+	return c.LoginWithScope(ctx, clientID, clientSecret, DefaultScope)
+}
+
+func (c *AuthClient) LoginWithScope(ctx context.Context, clientID, clientSecret, scope string) (*config.Credentials, error) {
 	tokenURL := fmt.Sprintf("%s%s", strings.TrimSuffix(c.config.Host, "/"), TokenEndpoint)
 
 	formData := url.Values{}
 	formData.Set("grant_type", "client_credentials")
 	formData.Set("client_id", clientID)
 	formData.Set("client_secret", clientSecret)
-	formData.Set("scope", DefaultScope)
+	formData.Set("scope", scope)
 
-	req, err := http.NewRequest("POST", tokenURL, strings.NewReader(formData.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", tokenURL, strings.NewReader(formData.Encode()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	if c.config.Realm != "" {
-		req.Header.Set(RealmHeaderName, c.config.Realm)
-	}
 
+	issuedAt := time.Now().UTC()
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to Polaris server: %w", err)
@@ -75,11 +102,7 @@ func (c *AuthClient) Login(clientID, clientSecret string) (*config.Credentials,
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		var oauthErr OAuthError
-		if err := json.Unmarshal(body, &oauthErr); err == nil && oauthErr.Error != "" {
-			return nil, fmt.Errorf("authentication failed: %s - %s", oauthErr.Error, oauthErr.ErrorDescription)
-		}
-		return nil, fmt.Errorf("authentication failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, tokenError("authentication failed", resp.StatusCode, body)
 	}
 
 	var tokenResp OAuthTokenResponse
@@ -95,6 +118,7 @@ func (c *AuthClient) Login(clientID, clientSecret string) (*config.Credentials,
 		AccessToken:  tokenResp.AccessToken,
 		TokenType:    tokenResp.TokenType,
 		ExpiresIn:    tokenResp.ExpiresIn,
+		IssuedAt:     issuedAt,
 		Scope:        tokenResp.Scope,
 		ClientID:     clientID,
 		ClientSecret: clientSecret,
@@ -103,24 +127,27 @@ func (c *AuthClient) Login(clientID, clientSecret string) (*config.Credentials,
 	return credentials, nil
 }
 
-func (c *AuthClient) RefreshToken(currentToken string) (*config.Credentials, error) {
+// ExchangeToken trades currentToken for a new one using the OAuth token
+// exchange grant, optionally narrowed to scope.
+func (c *AuthClient) ExchangeToken(ctx context.Context, currentToken, scope string) (*config.Credentials, error) {
 	tokenURL := fmt.Sprintf("%s%s", strings.TrimSuffix(c.config.Host, "/"), TokenEndpoint)
 
 	formData := url.Values{}
 	formData.Set("grant_type", "urn:ietf:params:oauth:grant-type:token-exchange")
 	formData.Set("subject_token", currentToken)
 	formData.Set("subject_token_type", "urn:ietf:params:oauth:token-type:access_token")
+	if scope != "" {
+		formData.Set("scope", scope)
+	}
 
-	req, err := http.NewRequest("POST", tokenURL, strings.NewReader(formData.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", tokenURL, strings.NewReader(formData.Encode()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	if c.config.Realm != "" {
-		req.Header.Set(RealmHeaderName, c.config.Realm)
-	}
 
+	issuedAt := time.Now().UTC()
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to Polaris server: %w", err)
@@ -133,11 +160,7 @@ func (c *AuthClient) RefreshToken(currentToken string) (*config.Credentials, err
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		var oauthErr OAuthError
-		if err := json.Unmarshal(body, &oauthErr); err == nil && oauthErr.Error != "" {
-			return nil, fmt.Errorf("token refresh failed: %s - %s", oauthErr.Error, oauthErr.ErrorDescription)
-		}
-		return nil, fmt.Errorf("token refresh failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, tokenError("token refresh failed", resp.StatusCode, body)
 	}
 
 	var tokenResp OAuthTokenResponse
@@ -149,23 +172,160 @@ func (c *AuthClient) RefreshToken(currentToken string) (*config.Credentials, err
 		return nil, fmt.Errorf("received empty access token from server")
 	}
 
-	existingCreds, _ := config.LoadCredentials()
-
 	credentials := &config.Credentials{
 		AccessToken: tokenResp.AccessToken,
 		TokenType:   tokenResp.TokenType,
 		ExpiresIn:   tokenResp.ExpiresIn,
+		IssuedAt:    issuedAt,
 		Scope:       tokenResp.Scope,
 	}
 
-	if existingCreds != nil {
-		credentials.ClientID = existingCreds.ClientID
-		credentials.ClientSecret = existingCreds.ClientSecret
+	return credentials, nil
+}
+
+// AssumeRole obtains a token limited to a single principal role, using the
+// stored client credentials if available and token exchange otherwise. The
+// result is attached to creds as its scoped token.
+func (c *AuthClient) AssumeRole(ctx context.Context, creds *config.Credentials, principalRole string) (*config.Credentials, error) {
+	scoped, err := c.scopedToken(ctx, creds, PrincipalRoleScope(principalRole))
+	if err != nil {
+		return nil, err
+	}
+
+	assumed := *creds
+	assumed.Scoped = scoped
+	return &assumed, nil
+}
+
+// LoginWithCredentialProcess logs in with the client credentials printed by
+// the profile's credential_process. The secret is not kept in the result.
+func (c *AuthClient) LoginWithCredentialProcess(ctx context.Context, scope string) (*config.Credentials, error) {
+	if c.config.CredentialProcess == "" {
+		return nil, fmt.Errorf("no credential_process is configured for profile %q", c.config.Name)
 	}
 
+	processCreds, err := config.RunCredentialProcess(c.config.CredentialProcess)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, err := c.LoginWithScope(ctx, processCreds.ClientID, processCreds.ClientSecret, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials.ClientSecret = ""
+	credentials.AuthMethod = AuthMethodCredentialProcess
 	return credentials, nil
 }
 
+func (c *AuthClient) scopedToken(ctx context.Context, creds *config.Credentials, scope string) (*config.Credentials, error) {
+	var (
+		scoped *config.Credentials
+		err    error
+	)
+	if creds.AuthMethod == AuthMethodCredentialProcess {
+		scoped, err = c.LoginWithCredentialProcess(ctx, scope)
+	} else if creds.ClientID != "" && creds.ClientSecret != "" {
+		scoped, err = c.LoginWithScope(ctx, creds.ClientID, creds.ClientSecret, scope)
+	} else {
+		scoped, err = c.ExchangeToken(ctx, creds.AccessToken, scope)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	scoped.ClientID = ""
+	scoped.ClientSecret = ""
+	scoped.AuthMethod = ""
+	if scoped.Scope == "" {
+		scoped.Scope = scope
+	}
+	return scoped, nil
+}
+
+// Renew re-acquires the full-scope token the same way it was first
+// obtained and, if a role is assumed, the scoped token as well.
+func (c *AuthClient) Renew(ctx context.Context, creds *config.Credentials) (*config.Credentials, error) {
+	var (
+		renewed *config.Credentials
+		err     error
+	)
+	if creds.AuthMethod == AuthMethodOIDC {
+		if creds.RefreshToken == "" {
+			return nil, unauthorizedf("the identity provider did not issue a refresh token. Run 'polaris auth login --oidc' again")
+		}
+		oidc, oidcErr := c.factory.OIDC()
+		if oidcErr != nil {
+			return nil, oidcErr
+		}
+		renewed, err = oidc.Refresh(ctx, creds.RefreshToken)
+	} else if creds.AuthMethod == AuthMethodCredentialProcess {
+		renewed, err = c.LoginWithCredentialProcess(ctx, loginScope(creds))
+	} else if creds.ClientID != "" && creds.ClientSecret != "" {
+		renewed, err = c.LoginWithScope(ctx, creds.ClientID, creds.ClientSecret, loginScope(creds))
+	} else {
+		renewed, err = c.ExchangeToken(ctx, creds.AccessToken, "")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep what the token endpoint does not send back, so that the renewed
+	// credentials can be renewed the same way again.
+	if renewed.ClientID == "" {
+		renewed.ClientID = creds.ClientID
+		renewed.ClientSecret = creds.ClientSecret
+	}
+	if renewed.AuthMethod == "" {
+		renewed.AuthMethod = creds.AuthMethod
+	}
+	if renewed.RefreshToken == "" {
+		renewed.RefreshToken = creds.RefreshToken
+	}
+	if renewed.Scope == "" {
+		renewed.Scope = creds.Scope
+	}
+
+	if creds.Scoped != nil {
+		renewed.Scoped, err = c.scopedToken(ctx, renewed, creds.Scoped.Scope)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return renewed, nil
+}
+
+func loginScope(creds *config.Credentials) string {
+	if creds.Scope != "" {
+		return creds.Scope
+	}
+	return DefaultScope
+}
+
 func (c *AuthClient) Logout() error {
 	return config.ClearCredentials()
 }
+
+// PrincipalRoleScope returns the OAuth scope that activates a single
+// principal role.
+func PrincipalRoleScope(principalRole string) string {
+	return principalRoleScopePrefix + principalRole
+}
+
+// ActiveRoles lists the principal roles granted by an OAuth scope string. A
+// nil result with all=true means every role of the principal is active.
+func ActiveRoles(scope string) (roles []string, all bool) {
+	for _, s := range strings.Fields(scope) {
+		role, ok := strings.CutPrefix(s, principalRoleScopePrefix)
+		if !ok {
+			continue
+		}
+		if role == "ALL" {
+			return nil, true
+		}
+		roles = append(roles, role)
+	}
+	return roles, false
+}
@@ -0,0 +1,206 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// CacheStatusHeader is set on responses served by the metadata cache to
+// "hit" when the server answered 304 Not Modified, and "miss" otherwise.
+const CacheStatusHeader = "X-Polaris-CLI-Cache"
+
+// cacheablePath matches the loadTable and loadView routes.
+var cacheablePath = regexp.MustCompile(`/v1/[^/]+/namespaces/[^/]+/(tables|views)/[^/]+$`)
+
+// MetadataCache keeps loadTable and loadView responses on disk and
+// revalidates them with If-None-Match. Entries are keyed by server, realm,
+// principal, path and query, so that profiles pointed at another server or
+// logged in as someone else never share them. Responses that carry vended
+// storage credentials are not cached.
+type MetadataCache struct {
+	dir string
+	// refresh skips revalidation and always stores a fresh copy.
+	refresh bool
+}
+
+// NewMetadataCache stores entries under dir, which should be specific to
+// the profile.
+func NewMetadataCache(dir string, refresh bool) *MetadataCache {
+	return &MetadataCache{dir: dir, refresh: refresh}
+}
+
+type cacheEntry struct {
+	Key      string          `json:"key"`
+	ETag     string          `json:"etag"`
+	Header   http.Header     `json:"header"`
+	StoredAt time.Time       `json:"stored_at"`
+	Body     json.RawMessage `json:"body"`
+}
+
+// cacheKey identifies what a request may be answered with: the same
+// resource on the same server, as seen by the same realm and principal.
+func cacheKey(req *http.Request) string {
+	return strings.Join([]string{
+		req.URL.Scheme + "://" + req.URL.Host + req.URL.EscapedPath() + "?" + req.URL.RawQuery,
+		"realm=" + req.Header.Get(RealmHeaderName),
+		"principal=" + cachePrincipal(req.Header.Get("Authorization")),
+	}, "\n")
+}
+
+// cachePrincipal names the caller of a request by the claims of its bearer
+// token, which stay the same when the token is renewed. Opaque tokens are
+// only known by their hash.
+func cachePrincipal(authorization string) string {
+	token := strings.TrimSpace(strings.TrimPrefix(authorization, "Bearer "))
+	if token == "" {
+		return ""
+	}
+	if claims, err := DecodeJWT(token); err == nil {
+		for _, id := range []string{claims.PrincipalID, claims.Subject, claims.ClientID} {
+			if id != "" {
+				return claims.Issuer + "|" + id
+			}
+		}
+	}
+	sum := sha256.Sum256([]byte(token))
+	return "token:" + hex.EncodeToString(sum[:])
+}
+
+func (c *MetadataCache) file(u string) string {
+	sum := sha256.Sum256([]byte(u))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (c *MetadataCache) load(key string) *cacheEntry {
+	data, err := os.ReadFile(c.file(key))
+	if err != nil {
+		return nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Key != key || entry.ETag == "" {
+		return nil
+	}
+	return &entry
+}
+
+func (c *MetadataCache) store(key string, entry *cacheEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	tmp := c.file(key) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return
+	}
+	os.Rename(tmp, c.file(key))
+}
+
+func (c *MetadataCache) remove(key string) {
+	os.Remove(c.file(key))
+}
+
+// CacheMiddleware serves loadTable and loadView from cache when the server
+// confirms with 304 Not Modified that the cached copy is current. The cached
+// copy is the response as the server sent it, body and headers. Requests
+// that ask for delegated storage access bypass the cache, since their
+// responses carry credentials. A nil cache disables it.
+func CacheMiddleware(cache *MetadataCache) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		if cache == nil {
+			return next
+		}
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Method != http.MethodGet || !cacheablePath.MatchString(req.URL.Path) || req.Header.Get("X-Iceberg-Access-Delegation") != "" {
+				return next.RoundTrip(req)
+			}
+
+			key := cacheKey(req)
+			var entry *cacheEntry
+			if !cache.refresh {
+				entry = cache.load(key)
+			}
+
+			r := req
+			if entry != nil {
+				r = req.Clone(req.Context())
+				r.Header.Set("If-None-Match", entry.ETag)
+			}
+
+			resp, err := next.RoundTrip(r)
+			if err != nil {
+				return nil, err
+			}
+
+			switch {
+			case resp.StatusCode == http.StatusNotModified && entry != nil:
+				resp.Body.Close()
+				resp.StatusCode = http.StatusOK
+				resp.Status = "200 OK"
+				resp.Header = entry.Header.Clone()
+				resp.Header.Set(CacheStatusHeader, "hit")
+				resp.ContentLength = int64(len(entry.Body))
+				resp.Body = io.NopCloser(bytes.NewReader(entry.Body))
+				return resp, nil
+
+			case resp.StatusCode == http.StatusOK && resp.Header.Get("ETag") != "":
+				body, err := io.ReadAll(resp.Body)
+				resp.Body.Close()
+				if err != nil {
+					return nil, err
+				}
+				resp.Body = io.NopCloser(bytes.NewReader(body))
+				if json.Valid(body) && !hasCredentials(body) {
+					header := resp.Header.Clone()
+					header.Del(CacheStatusHeader)
+					header.Del("Date")
+					cache.store(key, &cacheEntry{
+						Key:      key,
+						ETag:     resp.Header.Get("ETag"),
+						Header:   header,
+						StoredAt: time.Now().UTC(),
+						Body:     body,
+					})
+				} else {
+					cache.remove(key)
+				}
+				resp.Header.Set(CacheStatusHeader, "miss")
+				return resp, nil
+
+			case resp.StatusCode == http.StatusNotFound:
+				cache.remove(key)
+			}
+			return resp, nil
+		})
+	}
+}
+
+// hasCredentials reports whether a load result carries storage credentials,
+// in its storage-credentials list or as secrets in its config. Those are
+// never written to the cache.
+func hasCredentials(body []byte) bool {
+	var result struct {
+		Config             map[string]string `json:"config"`
+		StorageCredentials []json.RawMessage `json:"storage-credentials"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return true
+	}
+	if len(result.StorageCredentials) > 0 {
+		return true
+	}
+	for k := range result.Config {
+		if sensitiveKey(k) {
+			return true
+		}
+	}
+	return false
+}
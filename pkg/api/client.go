@@ -6,7 +6,6 @@ import (
 	"io"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/goravaa/apache-polaris-cli/pkg/config"
 )
@@ -20,7 +19,6 @@ const (
 type Client struct {
 	httpClient *http.Client
 	config     *config.Config
-	token      string
 }
 
 func NewClient() (*Client, error) {
@@ -29,28 +27,33 @@ func NewClient() (*Client, error) {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	token, err := config.GetAccessToken()
+	factory, err := NewFactory(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Client{
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		config: cfg,
-		token:  token,
-	}, nil
+	return newClient(factory)
 }
 
-func NewClientWithConfig(cfg *config.Config, token string) *Client {
-	return &Client{
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		config: cfg,
-		token:  token,
+func NewClientWithConfig(cfg *config.Config, token string) (*Client, error) {
+	factory, err := NewFactory(cfg, WithTokenProvider(NewStaticTokenProvider(token)))
+	if err != nil {
+		return nil, err
+	}
+
+	return newClient(factory)
+}
+
+func newClient(factory *Factory) (*Client, error) {
+	httpClient, err := factory.HTTPClient()
+	if err != nil {
+		return nil, err
 	}
+
+	return &Client{
+		httpClient: httpClient,
+		config:     factory.Config(),
+	}, nil
 }
 
 func (c *Client) doRequest(method, path string, body io.Reader) (*http.Response, error) {
@@ -62,11 +65,6 @@ func (c *Client) doRequest(method, path string, body io.Reader) (*http.Response,
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
-
-	if c.config.Realm != "" {
-		req.Header.Set(RealmHeaderName, c.config.Realm)
-	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -75,7 +73,7 @@ func (c *Client) doRequest(method, path string, body io.Reader) (*http.Response,
 
 	if resp.StatusCode == http.StatusUnauthorized {
 		resp.Body.Close()
-		return nil, fmt.Errorf("unauthorized: your session has expired. Please run 'polaris auth login' again")
+		return nil, unauthorizedf("unauthorized: your session has expired. Please run 'polaris auth login' again")
 	}
 
 	return resp, nil
@@ -94,7 +92,7 @@ func (c *Client) Get(path string) ([]byte, error) {
 	}
 
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, ResponseError(resp, body)
 	}
 
 	return body, nil
@@ -113,7 +111,7 @@ func (c *Client) Post(path string, body io.Reader) ([]byte, error) {
 	}
 
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(respBody))
+		return nil, ResponseError(resp, respBody)
 	}
 
 	return respBody, nil
@@ -132,7 +130,7 @@ func (c *Client) Put(path string, body io.Reader) ([]byte, error) {
 	}
 
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(respBody))
+		return nil, ResponseError(resp, respBody)
 	}
 
 	return respBody, nil
@@ -147,7 +145,7 @@ func (c *Client) Delete(path string) error {
 
 	if resp.StatusCode >= 400 {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(body))
+		return ResponseError(resp, body)
 	}
 
 	return nil
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Catalog API endpoints in the "<verb> <path>" form servers advertise in the
+// endpoints field of GET /v1/config.
+const (
+	EndpointListNamespaces  = "GET /v1/{prefix}/namespaces"
+	EndpointCreateNamespace = "POST /v1/{prefix}/namespaces"
+	EndpointListTables      = "GET /v1/{prefix}/namespaces/{namespace}/tables"
+	EndpointLoadTable       = "GET /v1/{prefix}/namespaces/{namespace}/tables/{table}"
+	EndpointListViews       = "GET /v1/{prefix}/namespaces/{namespace}/views"
+	EndpointLoadView        = "GET /v1/{prefix}/namespaces/{namespace}/views/{view}"
+	EndpointListPolicies    = "GET /polaris/v1/{prefix}/namespaces/{namespace}/policies"
+)
+
+// defaultEndpoints are assumed when a server does not send an endpoints
+// list. Per the Iceberg REST spec these are the namespace and table routes;
+// view and policy routes must be advertised explicitly.
+var defaultEndpoints = []string{
+	"GET /v1/{prefix}/namespaces",
+	"GET /v1/{prefix}/namespaces/{namespace}",
+	"HEAD /v1/{prefix}/namespaces/{namespace}",
+	"POST /v1/{prefix}/namespaces",
+	"POST /v1/{prefix}/namespaces/{namespace}/properties",
+	"DELETE /v1/{prefix}/namespaces/{namespace}",
+	"GET /v1/{prefix}/namespaces/{namespace}/tables",
+	"GET /v1/{prefix}/namespaces/{namespace}/tables/{table}",
+	"HEAD /v1/{prefix}/namespaces/{namespace}/tables/{table}",
+	"POST /v1/{prefix}/namespaces/{namespace}/tables",
+	"POST /v1/{prefix}/namespaces/{namespace}/tables/{table}",
+	"DELETE /v1/{prefix}/namespaces/{namespace}/tables/{table}",
+	"POST /v1/{prefix}/tables/rename",
+	"POST /v1/{prefix}/namespaces/{namespace}/register",
+	"POST /v1/{prefix}/namespaces/{namespace}/tables/{table}/metrics",
+	"POST /v1/{prefix}/transactions/commit",
+}
+
+// Capabilities is the set of catalog endpoints a server supports.
+type Capabilities struct {
+	endpoints  map[string]bool
+	advertised bool
+}
+
+// NewCapabilities builds the capability set from the endpoints field of a
+// catalog config response, falling back to the spec defaults when it is nil.
+func NewCapabilities(endpoints *[]string) *Capabilities {
+	c := &Capabilities{endpoints: map[string]bool{}}
+	list := defaultEndpoints
+	if endpoints != nil {
+		list = *endpoints
+		c.advertised = true
+	}
+	for _, e := range list {
+		c.endpoints[normalizeEndpoint(e)] = true
+	}
+	return c
+}
+
+// Advertised reports whether the server sent its own endpoints list.
+func (c *Capabilities) Advertised() bool {
+	return c.advertised
+}
+
+func (c *Capabilities) Supports(endpoint string) bool {
+	return c.endpoints[normalizeEndpoint(endpoint)]
+}
+
+// Require returns an EndpointNotSupportedError if the server does not
+// support endpoint.
+func (c *Capabilities) Require(endpoint string) error {
+	if c.Supports(endpoint) {
+		return nil
+	}
+	return &EndpointNotSupportedError{Endpoint: endpoint}
+}
+
+func normalizeEndpoint(e string) string {
+	verb, path, _ := strings.Cut(strings.TrimSpace(e), " ")
+	return strings.ToUpper(verb) + " " + strings.TrimSpace(path)
+}
+
+// EndpointNotSupportedError is returned instead of calling a route the
+// server does not list in its catalog config.
+type EndpointNotSupportedError struct {
+	Endpoint string
+}
+
+func (e *EndpointNotSupportedError) Error() string {
+	return fmt.Sprintf("server does not support %s", e.Endpoint)
+}
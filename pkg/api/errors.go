@@ -0,0 +1,187 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrUnauthorized matches errors caused by missing, expired or rejected
+// credentials, whether reported by the server or detected by the client.
+var ErrUnauthorized = errors.New("unauthorized")
+
+type unauthorizedError struct{ err error }
+
+func (e *unauthorizedError) Error() string { return e.err.Error() }
+
+func (e *unauthorizedError) Unwrap() error { return e.err }
+
+func (e *unauthorizedError) Is(target error) bool { return target == ErrUnauthorized }
+
+func unauthorizedf(format string, a ...any) error {
+	return &unauthorizedError{err: fmt.Errorf(format, a...)}
+}
+
+// APIError is an error response from the Polaris server, decoded from the
+// Iceberg REST IcebergErrorResponse body when one was sent. Every typed
+// error below wraps an APIError, so callers that only care about the status
+// or message can use errors.As with *APIError.
+type APIError struct {
+	StatusCode int
+	Type       string
+	Message    string
+	Stack      []string
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("request failed: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	if e.Type == "" {
+		return fmt.Sprintf("%s (HTTP %d)", e.Message, e.StatusCode)
+	}
+	return fmt.Sprintf("%s (%s, HTTP %d)", e.Message, e.Type, e.StatusCode)
+}
+
+// NotFoundError is returned when a resource other than a namespace, table or
+// view does not exist, such as a catalog or principal.
+type NotFoundError struct{ *APIError }
+
+func (e *NotFoundError) Unwrap() error { return e.APIError }
+
+type NoSuchNamespaceError struct{ *APIError }
+
+func (e *NoSuchNamespaceError) Unwrap() error { return e.APIError }
+
+type NoSuchTableError struct{ *APIError }
+
+func (e *NoSuchTableError) Unwrap() error { return e.APIError }
+
+type NoSuchViewError struct{ *APIError }
+
+func (e *NoSuchViewError) Unwrap() error { return e.APIError }
+
+type AlreadyExistsError struct{ *APIError }
+
+func (e *AlreadyExistsError) Unwrap() error { return e.APIError }
+
+// CommitFailedError is returned when a commit conflicts with a concurrent
+// change, including entity version mismatches on management updates.
+type CommitFailedError struct{ *APIError }
+
+func (e *CommitFailedError) Unwrap() error { return e.APIError }
+
+// CommitStateUnknownError means the server could not tell whether a commit
+// was applied. The operation must not be blindly retried.
+type CommitStateUnknownError struct{ *APIError }
+
+func (e *CommitStateUnknownError) Unwrap() error { return e.APIError }
+
+type BadRequestError struct{ *APIError }
+
+func (e *BadRequestError) Unwrap() error { return e.APIError }
+
+type NotAuthorizedError struct{ *APIError }
+
+func (e *NotAuthorizedError) Unwrap() error { return e.APIError }
+
+func (e *NotAuthorizedError) Is(target error) bool { return target == ErrUnauthorized }
+
+type ForbiddenError struct{ *APIError }
+
+func (e *ForbiddenError) Unwrap() error { return e.APIError }
+
+type UnsupportedError struct{ *APIError }
+
+func (e *UnsupportedError) Unwrap() error { return e.APIError }
+
+// ServerError covers 5xx responses and rate limiting; retrying later may
+// succeed.
+type ServerError struct{ *APIError }
+
+func (e *ServerError) Unwrap() error { return e.APIError }
+
+type errorResponse struct {
+	Error *struct {
+		Code    int      `json:"code"`
+		Message string   `json:"message"`
+		Type    string   `json:"type"`
+		Stack   []string `json:"stack"`
+	} `json:"error"`
+}
+
+// ResponseError decodes a failed response into one of the typed errors
+// above. The error type reported by the server takes precedence over the
+// HTTP status. It is meant for responses the caller did not expect and
+// always returns an error, even for a 2xx status.
+func ResponseError(resp *http.Response, body []byte) error {
+	if resp == nil {
+		return fmt.Errorf("request failed: no response")
+	}
+	if resp.StatusCode < 400 {
+		return fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+
+	var decoded errorResponse
+	if err := json.Unmarshal(body, &decoded); err == nil && decoded.Error != nil {
+		apiErr.Type = decoded.Error.Type
+		apiErr.Message = decoded.Error.Message
+		apiErr.Stack = decoded.Error.Stack
+	} else if text := strings.TrimSpace(string(body)); text != "" && !strings.HasPrefix(text, "{") && !strings.HasPrefix(text, "<") {
+		apiErr.Message = text
+	}
+
+	return typedError(apiErr)
+}
+
+func typedError(e *APIError) error {
+	switch strings.TrimSuffix(e.Type, "Exception") {
+	case "NoSuchNamespace":
+		return &NoSuchNamespaceError{e}
+	case "NoSuchTable":
+		return &NoSuchTableError{e}
+	case "NoSuchView":
+		return &NoSuchViewError{e}
+	case "NotFound", "NoSuchCatalog", "NoSuchPolicy", "NoSuchPrincipal", "NoSuchRole":
+		return &NotFoundError{e}
+	case "AlreadyExists", "EntityAlreadyExists":
+		return &AlreadyExistsError{e}
+	case "CommitFailed", "CommitConflict", "PolicyVersionMismatch":
+		return &CommitFailedError{e}
+	case "CommitStateUnknown":
+		return &CommitStateUnknownError{e}
+	case "BadRequest", "IllegalArgument", "Validation", "IllegalState":
+		return &BadRequestError{e}
+	case "NotAuthorized":
+		return &NotAuthorizedError{e}
+	case "Forbidden":
+		return &ForbiddenError{e}
+	case "UnsupportedOperation", "Unsupported":
+		return &UnsupportedError{e}
+	case "ServiceUnavailable", "ServiceFailure":
+		return &ServerError{e}
+	}
+
+	switch {
+	case e.StatusCode == http.StatusBadRequest:
+		return &BadRequestError{e}
+	case e.StatusCode == http.StatusUnauthorized || e.StatusCode == 419:
+		return &NotAuthorizedError{e}
+	case e.StatusCode == http.StatusForbidden:
+		return &ForbiddenError{e}
+	case e.StatusCode == http.StatusNotFound:
+		return &NotFoundError{e}
+	case e.StatusCode == http.StatusNotAcceptable || e.StatusCode == http.StatusNotImplemented:
+		return &UnsupportedError{e}
+	case e.StatusCode == http.StatusConflict:
+		return &AlreadyExistsError{e}
+	case e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500:
+		return &ServerError{e}
+	default:
+		return e
+	}
+}
@@ -0,0 +1,218 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	catalogapi "github.com/goravaa/apache-polaris-cli/pkg/api/openapi/catalog"
+	managementapi "github.com/goravaa/apache-polaris-cli/pkg/api/openapi/management"
+	"github.com/goravaa/apache-polaris-cli/pkg/config"
+)
+
+// Factory builds every client the CLI talks to Polaris with. All of them
+// share one base transport (TLS and proxy settings) and one middleware
+// chain: user-agent, realm, auth, metadata cache, retries, tracing and
+// logging, plus any extra middleware supplied with WithMiddleware. The
+// request timeout applies to each attempt rather than to the request as a
+// whole.
+type Factory struct {
+	config *config.Config
+
+	base      http.RoundTripper
+	anonymous http.RoundTripper
+	transport http.RoundTripper
+
+	tokens    *TokenProvider
+	auth      *AuthClient
+	noAuth    bool
+	logWriter io.Writer
+	logLevel  LogLevel
+	retry     RetryPolicy
+	cache     *MetadataCache
+	extra     []Middleware
+}
+
+type FactoryOption func(*Factory)
+
+// WithoutAuth builds a factory for unauthenticated calls only, such as
+// logging in. Catalog and Management clients are not available.
+func WithoutAuth() FactoryOption {
+	return func(f *Factory) { f.noAuth = true }
+}
+
+// WithTokenProvider uses tokens instead of resolving them from the profile.
+func WithTokenProvider(tokens *TokenProvider) FactoryOption {
+	return func(f *Factory) { f.tokens = tokens }
+}
+
+// WithLogger enables request logging to w at the given level.
+func WithLogger(w io.Writer, level LogLevel) FactoryOption {
+	return func(f *Factory) {
+		f.logWriter = w
+		f.logLevel = level
+	}
+}
+
+// WithRetryPolicy replaces the retry policy derived from the profile.
+func WithRetryPolicy(policy RetryPolicy) FactoryOption {
+	return func(f *Factory) { f.retry = policy }
+}
+
+// WithMetadataCache serves loadTable and loadView through cache.
+func WithMetadataCache(cache *MetadataCache) FactoryOption {
+	return func(f *Factory) { f.cache = cache }
+}
+
+// WithMiddleware appends middleware to the chain, after the built-in ones.
+func WithMiddleware(m ...Middleware) FactoryOption {
+	return func(f *Factory) { f.extra = append(f.extra, m...) }
+}
+
+func NewFactory(cfg *config.Config, opts ...FactoryOption) (*Factory, error) {
+	f := &Factory{
+		config: cfg,
+		retry:  RetryPolicyFromConfig(cfg),
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	base, err := NewTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+	f.base = base
+
+	f.anonymous = f.chain(base, nil)
+	f.auth = &AuthClient{
+		httpClient: f.newHTTPClient(f.anonymous),
+		config:     cfg,
+		factory:    f,
+	}
+
+	if f.noAuth {
+		return f, nil
+	}
+
+	if f.tokens == nil {
+		f.tokens, err = newTokenProvider(f.auth)
+		if err != nil {
+			return nil, err
+		}
+	}
+	f.transport = f.chain(base, AuthMiddleware(f.tokens))
+
+	return f, nil
+}
+
+func (f *Factory) chain(base http.RoundTripper, auth Middleware) http.RoundTripper {
+	middlewares := []Middleware{
+		UserAgentMiddleware(UserAgent),
+		RealmMiddleware(f.config.Realm),
+	}
+	if auth != nil {
+		middlewares = append(middlewares, auth)
+	}
+	middlewares = append(middlewares,
+		CacheMiddleware(f.cache),
+		RetryMiddleware(f.retry),
+		TracingMiddleware(),
+		LoggingMiddleware(f.logWriter, f.logLevel),
+	)
+	middlewares = append(middlewares, f.extra...)
+	middlewares = append(middlewares, TimeoutMiddleware(f.config.HTTPTimeout()))
+	return Chain(base, middlewares...)
+}
+
+func (f *Factory) newHTTPClient(transport http.RoundTripper) *http.Client {
+	return &http.Client{Transport: transport}
+}
+
+func (f *Factory) Config() *config.Config {
+	return f.config
+}
+
+func (f *Factory) Tokens() *TokenProvider {
+	return f.tokens
+}
+
+// Auth returns a client for the Polaris OAuth token endpoint.
+func (f *Factory) Auth() *AuthClient {
+	return f.auth
+}
+
+// OIDC returns a client for the profile's external identity provider. It
+// shares the base transport but sends no Polaris headers.
+func (f *Factory) OIDC() (*OIDCClient, error) {
+	if err := validateOIDC(f.config); err != nil {
+		return nil, err
+	}
+
+	transport := Chain(f.base,
+		UserAgentMiddleware(UserAgent),
+		RetryMiddleware(f.retry),
+		TracingMiddleware(),
+		LoggingMiddleware(f.logWriter, f.logLevel),
+		TimeoutMiddleware(f.config.HTTPTimeout()),
+	)
+	return &OIDCClient{
+		httpClient: f.newHTTPClient(transport),
+		settings:   f.config.OIDC,
+	}, nil
+}
+
+// HTTPClient returns an authenticated client for hand-built requests.
+func (f *Factory) HTTPClient() (*http.Client, error) {
+	if f.transport == nil {
+		return nil, fmt.Errorf("client factory was built without authentication")
+	}
+	return f.newHTTPClient(f.transport), nil
+}
+
+func (f *Factory) baseURL(path string) string {
+	return strings.TrimSuffix(f.config.Host, "/") + path
+}
+
+func (f *Factory) Catalog() (*catalogapi.ClientWithResponses, error) {
+	httpClient, err := f.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := catalogapi.NewClientWithResponses(
+		f.baseURL("/api/catalog"),
+		catalogapi.WithHTTPClient(httpClient),
+		catalogapi.WithRequestEditorFn(acceptJSON),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create catalog client: %w", err)
+	}
+	return client, nil
+}
+
+func (f *Factory) Management() (*managementapi.ClientWithResponses, error) {
+	httpClient, err := f.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := managementapi.NewClientWithResponses(
+		f.baseURL(ManagementAPIBase),
+		managementapi.WithHTTPClient(httpClient),
+		managementapi.WithRequestEditorFn(acceptJSON),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create management client: %w", err)
+	}
+	return client, nil
+}
+
+func acceptJSON(ctx context.Context, req *http.Request) error {
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json")
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+var ErrNotJWT = errors.New("token is not a JWT")
+
+// TokenClaims are the registered and Polaris-specific claims of an access
+// token.
+type TokenClaims struct {
+	Issuer      string         `json:"issuer,omitempty"`
+	Subject     string         `json:"subject,omitempty"`
+	PrincipalID string         `json:"principal_id,omitempty"`
+	ClientID    string         `json:"client_id,omitempty"`
+	Scopes      []string       `json:"scopes,omitempty"`
+	IssuedAt    *time.Time     `json:"issued_at,omitempty"`
+	ExpiresAt   *time.Time     `json:"expires_at,omitempty"`
+	Algorithm   string         `json:"algorithm,omitempty"`
+	KeyID       string         `json:"key_id,omitempty"`
+	Raw         map[string]any `json:"raw"`
+}
+
+// Expired reports whether the token carries an expiry that has passed.
+func (c *TokenClaims) Expired() bool {
+	return c.ExpiresAt != nil && time.Now().After(*c.ExpiresAt)
+}
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+// DecodeJWT parses the header and payload of a JWT without verifying its
+// signature.
+func DecodeJWT(token string) (*TokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrNotJWT
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: invalid header: %v", ErrNotJWT, err)
+	}
+
+	raw := map[string]any{}
+	if err := decodeSegment(parts[1], &raw); err != nil {
+		return nil, fmt.Errorf("%w: invalid payload: %v", ErrNotJWT, err)
+	}
+
+	claims := &TokenClaims{
+		Issuer:    claimString(raw["iss"]),
+		Subject:   claimString(raw["sub"]),
+		ClientID:  claimString(raw["client_id"]),
+		IssuedAt:  claimTime(raw["iat"]),
+		ExpiresAt: claimTime(raw["exp"]),
+		Algorithm: header.Algorithm,
+		KeyID:     header.KeyID,
+		Raw:       raw,
+	}
+	for _, key := range []string{"principalId", "principal_id"} {
+		if v, ok := raw[key]; ok {
+			claims.PrincipalID = claimString(v)
+			break
+		}
+	}
+	for _, key := range []string{"scope", "scp"} {
+		switch v := raw[key].(type) {
+		case string:
+			claims.Scopes = strings.Fields(v)
+		case []any:
+			for _, s := range v {
+				claims.Scopes = append(claims.Scopes, claimString(s))
+			}
+		}
+		if len(claims.Scopes) > 0 {
+			break
+		}
+	}
+
+	return claims, nil
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(segment, "="))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func claimString(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	default:
+		return fmt.Sprint(t)
+	}
+}
+
+func claimTime(v any) *time.Time {
+	f, ok := v.(float64)
+	if !ok {
+		return nil
+	}
+	t := time.Unix(int64(f), 0).UTC()
+	return &t
+}
+
+// asymmetricAlgorithms are the signature algorithms VerifyJWT accepts by
+// default. HMAC is only accepted when asked for: a symmetric key published
+// in a JWKS lets anyone who can read it sign tokens.
+var asymmetricAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.EdDSA,
+}
+
+var hmacAlgorithms = []jose.SignatureAlgorithm{jose.HS256, jose.HS384, jose.HS512}
+
+// curveAlgorithms pins each ECDSA curve to the one algorithm defined for it.
+var curveAlgorithms = map[string]jose.SignatureAlgorithm{
+	"P-256": jose.ES256,
+	"P-384": jose.ES384,
+	"P-521": jose.ES512,
+}
+
+// VerifyJWT checks the token's signature against the keys of a JWKS
+// document. The algorithm in the token header must fit the type of each key
+// it is checked with, and the key's own alg when it has one. RSA, ECDSA and
+// Ed25519 keys are supported; symmetric keys only with allowHMAC.
+func VerifyJWT(token string, jwks []byte, allowHMAC bool) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ErrNotJWT
+	}
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return fmt.Errorf("%w: invalid header: %v", ErrNotJWT, err)
+	}
+	alg := jose.SignatureAlgorithm(header.Algorithm)
+
+	algorithms := asymmetricAlgorithms
+	if allowHMAC {
+		algorithms = append(slices.Clone(asymmetricAlgorithms), hmacAlgorithms...)
+	} else if slices.Contains(hmacAlgorithms, alg) {
+		return fmt.Errorf("token is signed with %s and HMAC verification is not enabled", alg)
+	}
+	if !slices.Contains(algorithms, alg) {
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+
+	signed, err := jose.ParseSigned(token, algorithms)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrNotJWT, err)
+	}
+
+	var set struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := json.Unmarshal(jwks, &set); err != nil {
+		return fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+
+	var lastErr error
+	tried := 0
+	for _, raw := range set.Keys {
+		var key jose.JSONWebKey
+		if err := key.UnmarshalJSON(raw); err != nil {
+			// Keys of unknown types, or invalid ones such as EC points
+			// that are not on their curve, are skipped.
+			lastErr = fmt.Errorf("invalid key in JWKS: %w", err)
+			continue
+		}
+		if header.KeyID != "" && key.KeyID != header.KeyID {
+			continue
+		}
+		if key.Algorithm != "" && key.Algorithm != header.Algorithm {
+			continue
+		}
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		verifyKey, ok := keyFor(key, alg)
+		if !ok {
+			continue
+		}
+		tried++
+		if _, lastErr = signed.Verify(verifyKey); lastErr == nil {
+			return nil
+		}
+	}
+
+	if tried == 0 {
+		if lastErr != nil {
+			return fmt.Errorf("no usable key in the JWKS matches kid %q and alg %q: %w", header.KeyID, header.Algorithm, lastErr)
+		}
+		return fmt.Errorf("no key in the JWKS matches kid %q and alg %q", header.KeyID, header.Algorithm)
+	}
+	return fmt.Errorf("signature verification failed: %w", lastErr)
+}
+
+// keyFor returns the public or symmetric key to verify an alg signature
+// with, or false if the key is not of the type alg is defined for.
+func keyFor(key jose.JSONWebKey, alg jose.SignatureAlgorithm) (any, bool) {
+	if secret, ok := key.Key.([]byte); ok {
+		return secret, slices.Contains(hmacAlgorithms, alg)
+	}
+	if !key.IsPublic() {
+		key = key.Public()
+	}
+	switch k := key.Key.(type) {
+	case *rsa.PublicKey:
+		return k, strings.HasPrefix(string(alg), "RS") || strings.HasPrefix(string(alg), "PS")
+	case *ecdsa.PublicKey:
+		return k, curveAlgorithms[k.Curve.Params().Name] == alg
+	case ed25519.PublicKey:
+		return k, alg == jose.EdDSA
+	default:
+		return nil, false
+	}
+}
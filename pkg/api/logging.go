@@ -0,0 +1,219 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogLevel selects how much of the HTTP traffic is logged.
+type LogLevel int
+
+const (
+	LogOff LogLevel = iota
+	// LogVerbose logs the method, URL, status and latency of each request.
+	LogVerbose
+	// LogDebug also logs headers and JSON or form bodies.
+	LogDebug
+)
+
+const (
+	redacted         = "REDACTED"
+	maxLoggedBodyLen = 64 << 10
+)
+
+// LoggingMiddleware logs every attempt of every request to w. Credentials
+// are always redacted: the Authorization header, OAuth secrets and tokens,
+// and storage credentials vended by the catalog.
+func LoggingMiddleware(w io.Writer, level LogLevel) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		if w == nil || level == LogOff {
+			return next
+		}
+		var mu sync.Mutex
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			var buf bytes.Buffer
+			if level >= LogDebug {
+				fmt.Fprintf(&buf, "> %s %s\n", req.Method, redactURL(req.URL))
+				writeHeaders(&buf, "> ", req.Header)
+				if req.GetBody != nil && req.Body != nil && req.Body != http.NoBody {
+					if body, err := req.GetBody(); err == nil {
+						data, _ := io.ReadAll(io.LimitReader(body, maxLoggedBodyLen))
+						body.Close()
+						writeBody(&buf, "> ", req.Header.Get("Content-Type"), data)
+					}
+				}
+			}
+
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+			latency := time.Since(start).Round(time.Millisecond)
+
+			if err != nil {
+				fmt.Fprintf(&buf, "%s %s -> error: %v (%s)\n", req.Method, redactURL(req.URL), err, latency)
+			} else if level >= LogDebug {
+				fmt.Fprintf(&buf, "< %s (%s)\n", resp.Status, latency)
+				writeHeaders(&buf, "< ", resp.Header)
+				data, readErr := io.ReadAll(resp.Body)
+				resp.Body.Close()
+				resp.Body = io.NopCloser(bytes.NewReader(data))
+				if readErr != nil {
+					resp.Body = io.NopCloser(io.MultiReader(bytes.NewReader(data), errReader{readErr}))
+				}
+				if len(data) > maxLoggedBodyLen {
+					data = data[:maxLoggedBodyLen]
+				}
+				writeBody(&buf, "< ", resp.Header.Get("Content-Type"), data)
+			} else {
+				fmt.Fprintf(&buf, "%s %s -> %s (%s)\n", req.Method, redactURL(req.URL), resp.Status, latency)
+			}
+
+			mu.Lock()
+			w.Write(buf.Bytes())
+			mu.Unlock()
+			return resp, err
+		})
+	}
+}
+
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
+
+func writeHeaders(buf *bytes.Buffer, prefix string, header http.Header) {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range header[name] {
+			if sensitiveHeader(name) {
+				if scheme, _, ok := strings.Cut(value, " "); ok && strings.EqualFold(name, "Authorization") {
+					value = scheme + " " + redacted
+				} else {
+					value = redacted
+				}
+			}
+			fmt.Fprintf(buf, "%s%s: %s\n", prefix, name, value)
+		}
+	}
+}
+
+func writeBody(buf *bytes.Buffer, prefix, contentType string, data []byte) {
+	if len(data) == 0 {
+		return
+	}
+
+	var text string
+	switch {
+	case strings.Contains(contentType, "json"):
+		var v any
+		if err := json.Unmarshal(data, &v); err != nil {
+			text = fmt.Sprintf("[%d bytes of unparseable JSON]", len(data))
+			break
+		}
+		out, _ := json.MarshalIndent(redactJSON(v), "", "  ")
+		text = string(out)
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		values, err := url.ParseQuery(string(data))
+		if err != nil {
+			text = fmt.Sprintf("[%d bytes of form data]", len(data))
+			break
+		}
+		text = redactValues(values).Encode()
+	default:
+		text = fmt.Sprintf("[%d bytes of %s]", len(data), contentType)
+	}
+
+	buf.WriteString(prefix + "\n")
+	for _, line := range strings.Split(text, "\n") {
+		buf.WriteString(prefix + line + "\n")
+	}
+}
+
+// sensitiveOAuthKeys are OAuth and OIDC parameters that grant a token but
+// whose names do not say so: the authorization code, the PKCE verifier that
+// redeems it and the device code that is polled for a token.
+var sensitiveOAuthKeys = map[string]bool{
+	"code":             true,
+	"code_verifier":    true,
+	"device_code":      true,
+	"refresh_token":    true,
+	"id_token":         true,
+	"assertion":        true,
+	"client_assertion": true,
+}
+
+// sensitiveKey reports whether a JSON field, form field or query parameter
+// holds a credential. This covers OAuth parameters such as client_secret,
+// subject_token, code_verifier and refresh_token as well as vended storage
+// credentials like s3.secret-access-key or adls.sas-token.<account>.
+func sensitiveKey(key string) bool {
+	k := strings.ToLower(key)
+	switch {
+	case sensitiveOAuthKeys[k]:
+		return true
+	case strings.Contains(k, "secret"), strings.Contains(k, "password"),
+		strings.Contains(k, "access-key"), strings.Contains(k, "credential"):
+		return true
+	case strings.Contains(k, "token"):
+		return !strings.HasSuffix(k, "type") && !strings.Contains(k, "page")
+	default:
+		return false
+	}
+}
+
+func sensitiveHeader(name string) bool {
+	n := strings.ToLower(name)
+	return n == "authorization" || n == "proxy-authorization" || n == "cookie" || n == "set-cookie" || sensitiveKey(n)
+}
+
+func redactJSON(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, child := range t {
+			switch child.(type) {
+			case map[string]any, []any:
+				t[k] = redactJSON(child)
+			default:
+				if sensitiveKey(k) {
+					t[k] = redacted
+				}
+			}
+		}
+		return t
+	case []any:
+		for i, child := range t {
+			t[i] = redactJSON(child)
+		}
+		return t
+	default:
+		return v
+	}
+}
+
+func redactValues(values url.Values) url.Values {
+	for k := range values {
+		if sensitiveKey(k) {
+			values[k] = []string{redacted}
+		}
+	}
+	return values
+}
+
+func redactURL(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.Redacted()
+	}
+	r := *u
+	r.RawQuery = redactValues(u.Query()).Encode()
+	return r.Redacted()
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// UserAgent is sent with every request. The CLI sets it to include its
+// version.
+var UserAgent = "polaris-cli"
+
+// Middleware wraps a RoundTripper with additional behavior.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to http.RoundTripper.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Chain wraps base with middlewares. The first middleware sees the request
+// first and the response last.
+func Chain(base http.RoundTripper, middlewares ...Middleware) http.RoundTripper {
+	rt := base
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		rt = middlewares[i](rt)
+	}
+	return rt
+}
+
+// UserAgentMiddleware sets the User-Agent header.
+func UserAgentMiddleware(userAgent string) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			r := req.Clone(req.Context())
+			r.Header.Set("User-Agent", userAgent)
+			return next.RoundTrip(r)
+		})
+	}
+}
+
+// RealmMiddleware sets the Polaris realm header when a realm is configured.
+func RealmMiddleware(realm string) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		if realm == "" {
+			return next
+		}
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			r := req.Clone(req.Context())
+			r.Header.Set(RealmHeaderName, realm)
+			return next.RoundTrip(r)
+		})
+	}
+}
+
+// AuthMiddleware sets the bearer token and renews it once on a 401.
+func AuthMiddleware(tokens *TokenProvider) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return NewAuthTransport(tokens, next)
+	}
+}
+
+// TimeoutMiddleware bounds each individual attempt, including reading the
+// response body, to d. Unlike http.Client.Timeout it does not cover the
+// time spent waiting between retries.
+func TimeoutMiddleware(d time.Duration) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		if d <= 0 {
+			return next
+		}
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			ctx, cancel := context.WithTimeout(req.Context(), d)
+			resp, err := next.RoundTrip(req.WithContext(ctx))
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		})
+	}
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// TracingMiddleware records an OpenTelemetry client span for every request
+// attempt and propagates the W3C trace context to the server.
+func TracingMiddleware() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return otelhttp.NewTransport(next,
+			otelhttp.WithSpanNameFormatter(func(_ string, req *http.Request) string {
+				return req.Method + " " + req.URL.Path
+			}),
+		)
+	}
+}
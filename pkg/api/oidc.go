@@ -0,0 +1,399 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/goravaa/apache-polaris-cli/pkg/config"
+)
+
+const (
+	oidcDiscoveryPath   = "/.well-known/openid-configuration"
+	oidcCallbackPath    = "/callback"
+	deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+	defaultOIDCScopes   = "openid offline_access"
+)
+
+type oidcDiscovery struct {
+	Issuer                      string `json:"issuer"`
+	AuthorizationEndpoint       string `json:"authorization_endpoint"`
+	TokenEndpoint               string `json:"token_endpoint"`
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+}
+
+type oidcTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	Scope        string `json:"scope"`
+}
+
+// DeviceAuthorization is what the user needs to complete a device-code
+// login on another machine.
+type DeviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// OIDCClient obtains Polaris access tokens from an external OpenID Connect
+// identity provider configured on the profile.
+type OIDCClient struct {
+	httpClient *http.Client
+	settings   *config.OIDCConfig
+	discovery  *oidcDiscovery
+}
+
+func NewOIDCClient(cfg *config.Config) (*OIDCClient, error) {
+	factory, err := NewFactory(cfg, WithoutAuth())
+	if err != nil {
+		return nil, err
+	}
+	return factory.OIDC()
+}
+
+func validateOIDC(cfg *config.Config) error {
+	if cfg.OIDC == nil || cfg.OIDC.Issuer == "" || cfg.OIDC.ClientID == "" {
+		return fmt.Errorf("OIDC is not configured for profile %q. Run 'polaris config set --oidc-issuer <url> --oidc-client-id <id>' first", cfg.Name)
+	}
+	return nil
+}
+
+func (c *OIDCClient) discover(ctx context.Context) (*oidcDiscovery, error) {
+	if c.discovery != nil {
+		return c.discovery, nil
+	}
+
+	discoveryURL := strings.TrimSuffix(c.settings.Issuer, "/") + oidcDiscoveryPath
+	req, err := http.NewRequestWithContext(ctx, "GET", discoveryURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to identity provider: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OIDC discovery failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var discovery oidcDiscovery
+	if err := json.Unmarshal(body, &discovery); err != nil {
+		return nil, fmt.Errorf("failed to parse OIDC discovery document: %w", err)
+	}
+	if discovery.TokenEndpoint == "" {
+		return nil, fmt.Errorf("OIDC discovery document at %s has no token_endpoint", discoveryURL)
+	}
+
+	c.discovery = &discovery
+	return c.discovery, nil
+}
+
+func (c *OIDCClient) scopes() string {
+	if c.settings.Scopes != "" {
+		return c.settings.Scopes
+	}
+	return defaultOIDCScopes
+}
+
+// LoginBrowser runs the authorization-code flow with PKCE. It listens on a
+// loopback port for the redirect and calls open with the authorization URL.
+func (c *OIDCClient) LoginBrowser(ctx context.Context, open func(authURL string) error) (*config.Credentials, error) {
+	discovery, err := c.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if discovery.AuthorizationEndpoint == "" {
+		return nil, fmt.Errorf("identity provider does not advertise an authorization_endpoint; use the device-code flow instead")
+	}
+
+	verifier, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+	state, err := randomString(16)
+	if err != nil {
+		return nil, err
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", c.settings.RedirectPort))
+	if err != nil {
+		return nil, fmt.Errorf("failed to start loopback listener: %w", err)
+	}
+	defer listener.Close()
+
+	redirectURI := fmt.Sprintf("http://%s%s", listener.Addr().String(), oidcCallbackPath)
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", c.settings.ClientID)
+	params.Set("redirect_uri", redirectURI)
+	params.Set("scope", c.scopes())
+	params.Set("state", state)
+	params.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	params.Set("code_challenge_method", "S256")
+	if c.settings.Audience != "" {
+		params.Set("audience", c.settings.Audience)
+	}
+
+	authURL := discovery.AuthorizationEndpoint
+	if strings.Contains(authURL, "?") {
+		authURL += "&" + params.Encode()
+	} else {
+		authURL += "?" + params.Encode()
+	}
+
+	type result struct {
+		code string
+		err  error
+	}
+	results := make(chan result, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(oidcCallbackPath, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		var res result
+		switch {
+		case q.Get("state") != state:
+			res.err = errors.New("OIDC callback state mismatch")
+		case q.Get("error") != "":
+			res.err = fmt.Errorf("authorization failed: %s - %s", q.Get("error"), q.Get("error_description"))
+		case q.Get("code") == "":
+			res.err = errors.New("OIDC callback did not include an authorization code")
+		default:
+			res.code = q.Get("code")
+		}
+
+		if res.err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "<html><body><h3>Login failed</h3><p>%s</p></body></html>", html.EscapeString(res.err.Error()))
+		} else {
+			fmt.Fprint(w, "<html><body><h3>Login complete</h3><p>You can close this window and return to the terminal.</p></body></html>")
+		}
+
+		select {
+		case results <- res:
+		default:
+		}
+	})
+
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go server.Serve(listener)
+	defer server.Close()
+
+	if err := open(authURL); err != nil {
+		return nil, err
+	}
+
+	var res result
+	select {
+	case res = <-results:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("timed out waiting for the browser login: %w", ctx.Err())
+	}
+	if res.err != nil {
+		return nil, res.err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", res.code)
+	form.Set("redirect_uri", redirectURI)
+	form.Set("client_id", c.settings.ClientID)
+	form.Set("code_verifier", verifier)
+
+	return c.requestToken(ctx, discovery.TokenEndpoint, form)
+}
+
+// StartDeviceLogin begins the device-code flow. The caller shows the result
+// to the user and then calls PollDeviceLogin.
+func (c *OIDCClient) StartDeviceLogin(ctx context.Context) (*DeviceAuthorization, error) {
+	discovery, err := c.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if discovery.DeviceAuthorizationEndpoint == "" {
+		return nil, fmt.Errorf("identity provider does not advertise a device_authorization_endpoint")
+	}
+
+	form := url.Values{}
+	form.Set("client_id", c.settings.ClientID)
+	form.Set("scope", c.scopes())
+	if c.settings.Audience != "" {
+		form.Set("audience", c.settings.Audience)
+	}
+
+	body, status, err := c.postForm(ctx, discovery.DeviceAuthorizationEndpoint, form)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, tokenError("device authorization failed", status, body)
+	}
+
+	var auth DeviceAuthorization
+	if err := json.Unmarshal(body, &auth); err != nil {
+		return nil, fmt.Errorf("failed to parse device authorization response: %w", err)
+	}
+	if auth.DeviceCode == "" || auth.UserCode == "" {
+		return nil, fmt.Errorf("device authorization response is missing device_code or user_code")
+	}
+	if auth.Interval <= 0 {
+		auth.Interval = 5
+	}
+
+	return &auth, nil
+}
+
+// PollDeviceLogin polls the token endpoint until the user approves or
+// denies the device-code request, or it expires.
+func (c *OIDCClient) PollDeviceLogin(ctx context.Context, auth *DeviceAuthorization) (*config.Credentials, error) {
+	discovery, err := c.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	interval := time.Duration(auth.Interval) * time.Second
+	if auth.ExpiresIn > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(auth.ExpiresIn)*time.Second)
+		defer cancel()
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", deviceCodeGrantType)
+	form.Set("device_code", auth.DeviceCode)
+	form.Set("client_id", c.settings.ClientID)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("device login was not completed in time: %w", ctx.Err())
+		case <-time.After(interval):
+		}
+
+		body, status, err := c.postForm(ctx, discovery.TokenEndpoint, form)
+		if err != nil {
+			return nil, err
+		}
+		if status == http.StatusOK {
+			return c.parseToken(body)
+		}
+
+		var oauthErr OAuthError
+		_ = json.Unmarshal(body, &oauthErr)
+		switch oauthErr.Error {
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * time.Second
+		default:
+			return nil, tokenError("device login failed", status, body)
+		}
+	}
+}
+
+// Refresh exchanges a refresh token for a new access token.
+func (c *OIDCClient) Refresh(ctx context.Context, refreshToken string) (*config.Credentials, error) {
+	discovery, err := c.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "refresh_token")
+	form.Set("refresh_token", refreshToken)
+	form.Set("client_id", c.settings.ClientID)
+
+	creds, err := c.requestToken(ctx, discovery.TokenEndpoint, form)
+	if err != nil {
+		return nil, err
+	}
+	if creds.RefreshToken == "" {
+		creds.RefreshToken = refreshToken
+	}
+	return creds, nil
+}
+
+func (c *OIDCClient) requestToken(ctx context.Context, tokenURL string, form url.Values) (*config.Credentials, error) {
+	body, status, err := c.postForm(ctx, tokenURL, form)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, tokenError("token request failed", status, body)
+	}
+	return c.parseToken(body)
+}
+
+func (c *OIDCClient) parseToken(body []byte) (*config.Credentials, error) {
+	var tokenResp oidcTokenResponse
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return nil, fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if tokenResp.AccessToken == "" {
+		return nil, fmt.Errorf("received empty access token from identity provider")
+	}
+
+	return &config.Credentials{
+		AccessToken:  tokenResp.AccessToken,
+		TokenType:    tokenResp.TokenType,
+		ExpiresIn:    tokenResp.ExpiresIn,
+		IssuedAt:     time.Now().UTC(),
+		Scope:        tokenResp.Scope,
+		RefreshToken: tokenResp.RefreshToken,
+		AuthMethod:   AuthMethodOIDC,
+	}, nil
+}
+
+func (c *OIDCClient) postForm(ctx context.Context, endpoint string, form url.Values) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to connect to identity provider: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read response: %w", err)
+	}
+	return body, resp.StatusCode, nil
+}
+
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random value: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,312 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goravaa/apache-polaris-cli/pkg/config"
+)
+
+// fakeIdP is a stand-in OpenID Connect provider that supports the
+// authorization-code flow with PKCE, the device-code flow and refresh
+// tokens. It checks the requests the CLI makes the way a real provider
+// would.
+type fakeIdP struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu         sync.Mutex
+	challenges map[string]string // authorization code -> PKCE challenge
+	devicePoll int
+	refreshes  int
+}
+
+const (
+	fakeClientID   = "polaris-cli"
+	fakeDeviceCode = "device-123"
+	fakeUserCode   = "ABCD-EFGH"
+)
+
+func newFakeIdP(t *testing.T) *fakeIdP {
+	idp := &fakeIdP{t: t, challenges: map[string]string{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc(oidcDiscoveryPath, idp.discovery)
+	mux.HandleFunc("/authorize", idp.authorize)
+	mux.HandleFunc("/device", idp.device)
+	mux.HandleFunc("/token", idp.token)
+	mux.HandleFunc(TokenEndpoint, idp.polarisToken)
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func (idp *fakeIdP) client(t *testing.T) *OIDCClient {
+	t.Helper()
+	client, err := NewOIDCClient(idp.config())
+	if err != nil {
+		t.Fatalf("NewOIDCClient: %v", err)
+	}
+	return client
+}
+
+func (idp *fakeIdP) config() *config.Config {
+	return &config.Config{
+		Name: "test",
+		Host: idp.server.URL,
+		OIDC: &config.OIDCConfig{Issuer: idp.server.URL, ClientID: fakeClientID},
+	}
+}
+
+func (idp *fakeIdP) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                        idp.server.URL,
+		"authorization_endpoint":        idp.server.URL + "/authorize",
+		"token_endpoint":                idp.server.URL + "/token",
+		"device_authorization_endpoint": idp.server.URL + "/device",
+	})
+}
+
+// authorize plays the part of the user's browser and the provider's login
+// page: it checks the request and redirects back with a code.
+func (idp *fakeIdP) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("response_type") != "code" || q.Get("client_id") != fakeClientID {
+		http.Error(w, "bad authorization request", http.StatusBadRequest)
+		return
+	}
+	if q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		http.Error(w, "PKCE is required", http.StatusBadRequest)
+		return
+	}
+
+	idp.mu.Lock()
+	code := fmt.Sprintf("code-%d", len(idp.challenges))
+	idp.challenges[code] = q.Get("code_challenge")
+	idp.mu.Unlock()
+
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil {
+		http.Error(w, "bad redirect_uri", http.StatusBadRequest)
+		return
+	}
+	back := redirect.Query()
+	back.Set("code", code)
+	back.Set("state", q.Get("state"))
+	redirect.RawQuery = back.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (idp *fakeIdP) device(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("client_id") != fakeClientID {
+		writeJSON(w, http.StatusBadRequest, OAuthError{Error: "invalid_client"})
+		return
+	}
+	writeJSON(w, http.StatusOK, DeviceAuthorization{
+		DeviceCode:      fakeDeviceCode,
+		UserCode:        fakeUserCode,
+		VerificationURI: idp.server.URL + "/activate",
+		ExpiresIn:       30,
+		Interval:        1,
+	})
+}
+
+func (idp *fakeIdP) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, OAuthError{Error: "invalid_request"})
+		return
+	}
+	form := r.PostForm
+	if form.Get("client_id") != fakeClientID {
+		writeJSON(w, http.StatusUnauthorized, OAuthError{Error: "invalid_client"})
+		return
+	}
+
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+
+	switch form.Get("grant_type") {
+	case "authorization_code":
+		challenge, ok := idp.challenges[form.Get("code")]
+		delete(idp.challenges, form.Get("code"))
+		sum := sha256.Sum256([]byte(form.Get("code_verifier")))
+		if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != challenge {
+			writeJSON(w, http.StatusBadRequest, OAuthError{Error: "invalid_grant", ErrorDescription: "PKCE verification failed"})
+			return
+		}
+		writeJSON(w, http.StatusOK, oidcTokenResponse{AccessToken: "browser-token", TokenType: "Bearer", ExpiresIn: 300, RefreshToken: "refresh-1"})
+
+	case deviceCodeGrantType:
+		if form.Get("device_code") != fakeDeviceCode {
+			writeJSON(w, http.StatusBadRequest, OAuthError{Error: "invalid_grant"})
+			return
+		}
+		idp.devicePoll++
+		if idp.devicePoll == 1 {
+			writeJSON(w, http.StatusBadRequest, OAuthError{Error: "authorization_pending"})
+			return
+		}
+		writeJSON(w, http.StatusOK, oidcTokenResponse{AccessToken: "device-token", TokenType: "Bearer", ExpiresIn: 300, RefreshToken: "refresh-1"})
+
+	case "refresh_token":
+		if form.Get("refresh_token") != "refresh-1" {
+			writeJSON(w, http.StatusBadRequest, OAuthError{Error: "invalid_grant"})
+			return
+		}
+		idp.refreshes++
+		// Like many providers, the refresh token is not rotated.
+		writeJSON(w, http.StatusOK, oidcTokenResponse{AccessToken: fmt.Sprintf("refreshed-%d", idp.refreshes), TokenType: "Bearer", ExpiresIn: 300})
+
+	default:
+		writeJSON(w, http.StatusBadRequest, OAuthError{Error: "unsupported_grant_type"})
+	}
+}
+
+// polarisToken stands in for the Polaris token endpoint, which narrows an
+// identity provider's token to a principal role by token exchange.
+func (idp *fakeIdP) polarisToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("subject_token") == "" {
+		writeJSON(w, http.StatusBadRequest, OAuthError{Error: "invalid_request"})
+		return
+	}
+	writeJSON(w, http.StatusOK, OAuthTokenResponse{
+		AccessToken: "scoped-" + r.PostForm.Get("subject_token"),
+		TokenType:   "Bearer",
+		ExpiresIn:   300,
+		Scope:       r.PostForm.Get("scope"),
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func TestOIDCLoginBrowserPKCE(t *testing.T) {
+	idp := newFakeIdP(t)
+	client := idp.client(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// The browser follows the provider's redirect to the loopback callback.
+	browser := func(authURL string) error {
+		go func() {
+			resp, err := http.Get(authURL)
+			if err != nil {
+				t.Errorf("browser: %v", err)
+				return
+			}
+			resp.Body.Close()
+		}()
+		return nil
+	}
+
+	creds, err := client.LoginBrowser(ctx, browser)
+	if err != nil {
+		t.Fatalf("LoginBrowser: %v", err)
+	}
+	if creds.AccessToken != "browser-token" || creds.RefreshToken != "refresh-1" || creds.AuthMethod != AuthMethodOIDC {
+		t.Errorf("credentials = %+v", creds)
+	}
+}
+
+func TestOIDCLoginBrowserRejectsWrongVerifier(t *testing.T) {
+	idp := newFakeIdP(t)
+	client := idp.client(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Swap the challenge for one that does not match the verifier.
+	browser := func(authURL string) error {
+		u, err := url.Parse(authURL)
+		if err != nil {
+			return err
+		}
+		q := u.Query()
+		q.Set("code_challenge", base64.RawURLEncoding.EncodeToString([]byte("not-the-challenge")))
+		u.RawQuery = q.Encode()
+		go func() {
+			if resp, err := http.Get(u.String()); err == nil {
+				resp.Body.Close()
+			}
+		}()
+		return nil
+	}
+
+	if _, err := client.LoginBrowser(ctx, browser); err == nil {
+		t.Fatal("LoginBrowser succeeded with a mismatched PKCE challenge")
+	}
+}
+
+func TestOIDCDeviceLogin(t *testing.T) {
+	idp := newFakeIdP(t)
+	client := idp.client(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	auth, err := client.StartDeviceLogin(ctx)
+	if err != nil {
+		t.Fatalf("StartDeviceLogin: %v", err)
+	}
+	if auth.UserCode != fakeUserCode {
+		t.Errorf("user code = %q, want %q", auth.UserCode, fakeUserCode)
+	}
+
+	creds, err := client.PollDeviceLogin(ctx, auth)
+	if err != nil {
+		t.Fatalf("PollDeviceLogin: %v", err)
+	}
+	if creds.AccessToken != "device-token" || creds.RefreshToken != "refresh-1" {
+		t.Errorf("credentials = %+v", creds)
+	}
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	if idp.devicePoll != 2 {
+		t.Errorf("token endpoint polled %d times, want 2", idp.devicePoll)
+	}
+}
+
+func TestRenewKeepsOIDCRefreshToken(t *testing.T) {
+	idp := newFakeIdP(t)
+	auth, err := NewAuthClient(idp.config())
+	if err != nil {
+		t.Fatalf("NewAuthClient: %v", err)
+	}
+
+	creds := &config.Credentials{
+		AccessToken:  "device-token",
+		Scope:        "openid offline_access",
+		RefreshToken: "refresh-1",
+		AuthMethod:   AuthMethodOIDC,
+		Scoped:       &config.Credentials{AccessToken: "scoped-device-token", Scope: PrincipalRoleScope("reader")},
+	}
+	// Renewing twice shows that the first renewal kept what the second one
+	// needs.
+	for i := 1; i <= 2; i++ {
+		creds, err = auth.Renew(context.Background(), creds)
+		if err != nil {
+			t.Fatalf("Renew #%d: %v", i, err)
+		}
+		if want := fmt.Sprintf("refreshed-%d", i); creds.AccessToken != want {
+			t.Errorf("Renew #%d: access token = %q, want %q", i, creds.AccessToken, want)
+		}
+		if creds.RefreshToken != "refresh-1" || creds.AuthMethod != AuthMethodOIDC || creds.Scope != "openid offline_access" {
+			t.Errorf("Renew #%d: credentials = %+v", i, creds)
+		}
+		if creds.Scoped == nil || creds.Scoped.AccessToken != "scoped-"+creds.AccessToken || creds.Scoped.Scope != PrincipalRoleScope("reader") {
+			t.Errorf("Renew #%d: assumed role = %+v", i, creds.Scoped)
+		}
+	}
+}
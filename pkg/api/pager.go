@@ -0,0 +1,118 @@
+package api
+
+import "context"
+
+// Page is one page of a paginated list response.
+type Page[T any] struct {
+	Items []T
+	// NextPageToken is empty on the last page.
+	NextPageToken string
+}
+
+// PageRequest is passed to a PageFunc. A nil Token asks the server for all
+// results in one response, as the Iceberg REST spec requires servers to
+// return everything when pageToken is absent.
+type PageRequest struct {
+	Token *string
+	Size  *int
+}
+
+// PageFunc fetches a single page.
+type PageFunc[T any] func(ctx context.Context, req PageRequest) (Page[T], error)
+
+type PageOptions struct {
+	// Size is the requested page size; zero leaves it to the server.
+	Size int
+	// Token resumes listing from a next-page-token of an earlier call.
+	Token string
+	// All follows next-page tokens until the last page.
+	All bool
+	// MaxItems stops after this many items; zero means no limit.
+	MaxItems int
+}
+
+// paginated reports whether the first request must opt into pagination by
+// sending a pageToken.
+func (o PageOptions) paginated() bool {
+	return o.Token != "" || o.Size > 0 || o.All || o.MaxItems > 0
+}
+
+// Progress is how far Paginate got.
+type Progress struct {
+	// Count is the number of items passed to fn.
+	Count int
+	// Next is the token to resume from. It is empty once the listing is
+	// complete, and when it was truncated.
+	Next string
+	// Truncated is set when MaxItems ended the listing part way through a
+	// page. The items left on that page cannot be resumed from a token.
+	Truncated bool
+}
+
+// Paginate fetches pages and passes the items of each page to fn as soon as
+// it arrives. Without All it stops after the first page. With MaxItems, the
+// page size requested is capped to the items still needed, so that the
+// listing normally stops at the end of a page and can be resumed from
+// Progress.Next. The progress is also returned with an error, for example
+// when ctx is cancelled, so callers can report partial results.
+func Paginate[T any](ctx context.Context, fetch PageFunc[T], opts PageOptions, fn func([]T) error) (Progress, error) {
+	req := PageRequest{}
+	if opts.paginated() {
+		token := opts.Token
+		req.Token = &token
+	}
+
+	progress := Progress{Next: opts.Token}
+	for {
+		if err := ctx.Err(); err != nil {
+			return progress, err
+		}
+
+		req.Size = opts.pageSize(progress.Count)
+		page, err := fetch(ctx, req)
+		if err != nil {
+			return progress, err
+		}
+
+		items := page.Items
+		truncated := false
+		if remaining := opts.MaxItems - progress.Count; opts.MaxItems > 0 && len(items) > remaining {
+			// The server sent more than was asked for. Neither this page's
+			// token nor the next one resumes after the items kept.
+			items = items[:remaining]
+			truncated = true
+		}
+		if err := fn(items); err != nil {
+			return progress, err
+		}
+		progress.Count += len(items)
+		if truncated {
+			progress.Next = ""
+			progress.Truncated = true
+			return progress, nil
+		}
+
+		progress.Next = page.NextPageToken
+		if progress.Next == "" || !opts.All || (opts.MaxItems > 0 && progress.Count >= opts.MaxItems) {
+			return progress, nil
+		}
+
+		token := progress.Next
+		req.Token = &token
+	}
+}
+
+// pageSize returns the page size to request once count items have been
+// listed: the requested size, capped to the items MaxItems still allows.
+func (o PageOptions) pageSize(count int) *int {
+	size := o.Size
+	if o.MaxItems > 0 {
+		if remaining := o.MaxItems - count; size == 0 || size > remaining {
+			size = remaining
+		}
+	}
+	if size <= 0 {
+		return nil
+	}
+	return &size
+}
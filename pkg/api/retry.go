@@ -0,0 +1,178 @@
+package api
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goravaa/apache-polaris-cli/pkg/config"
+)
+
+const (
+	DefaultRetryBaseDelay = 250 * time.Millisecond
+	DefaultRetryMaxDelay  = 20 * time.Second
+)
+
+// RetryPolicy controls how transient failures are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	MaxAttempts int
+	// Deadline bounds the total time spent on one request across all
+	// attempts and backoff delays. Zero means no bound.
+	Deadline  time.Duration
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+func RetryPolicyFromConfig(cfg *config.Config) RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: cfg.RetryMaxAttempts(),
+		Deadline:    cfg.RetryDeadlineDuration(),
+		BaseDelay:   DefaultRetryBaseDelay,
+		MaxDelay:    DefaultRetryMaxDelay,
+	}
+}
+
+// RetryMiddleware retries requests that fail with 429, 502, 503, 504 or a
+// connection error, using exponential backoff with full jitter and honoring
+// Retry-After. GET, HEAD, PUT, DELETE and OPTIONS are retried; POST only
+// for the OAuth token endpoint. Requests made with a context from
+// WithoutRetry are sent once.
+func RetryMiddleware(policy RetryPolicy) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		if policy.MaxAttempts <= 1 {
+			return next
+		}
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if !retryable(req) || req.Context().Value(noRetryKey{}) != nil || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+				return next.RoundTrip(req)
+			}
+
+			start := time.Now()
+			ctx := req.Context()
+			for attempt := 1; ; attempt++ {
+				r := req
+				if attempt > 1 && req.GetBody != nil {
+					body, err := req.GetBody()
+					if err != nil {
+						return nil, err
+					}
+					r = req.Clone(ctx)
+					r.Body = body
+				}
+
+				resp, err := next.RoundTrip(r)
+				if attempt >= policy.MaxAttempts || !transient(ctx, resp, err) {
+					return resp, err
+				}
+
+				delay := policy.backoff(attempt)
+				if resp != nil {
+					if after, ok := retryAfter(resp); ok {
+						delay = after
+					}
+				}
+				if policy.Deadline > 0 && time.Since(start)+delay > policy.Deadline {
+					return resp, err
+				}
+
+				if resp != nil {
+					io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+					resp.Body.Close()
+				}
+
+				timer := time.NewTimer(delay)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return nil, ctx.Err()
+				case <-timer.C:
+				}
+			}
+		})
+	}
+}
+
+type noRetryKey struct{}
+
+// WithoutRetry returns a context whose requests RetryMiddleware does not
+// retry. Conditional updates use it: if the response to the first attempt
+// is lost, a retry would fail with a conflict against the change it made.
+func WithoutRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRetryKey{}, true)
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	base := p.BaseDelay
+	if base <= 0 {
+		base = DefaultRetryBaseDelay
+	}
+	maxDelay := p.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = DefaultRetryMaxDelay
+	}
+
+	ceiling := base << (attempt - 1)
+	if ceiling <= 0 || ceiling > maxDelay {
+		ceiling = maxDelay
+	}
+	return time.Duration(rand.Int64N(int64(ceiling)) + 1)
+}
+
+func retryable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	case http.MethodPost:
+		// Token requests have no side effects on the server.
+		return strings.HasSuffix(req.URL.Path, "/oauth/tokens")
+	default:
+		return false
+	}
+}
+
+func transient(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		var certErr *x509.UnknownAuthorityError
+		var hostErr x509.HostnameError
+		var invalidErr x509.CertificateInvalidError
+		if errors.As(err, &certErr) || errors.As(err, &hostErr) || errors.As(err, &invalidErr) {
+			return false
+		}
+		return !errors.Is(err, context.Canceled)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP
+// date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
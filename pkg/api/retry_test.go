@@ -0,0 +1,54 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryMiddlewareWithoutRetry(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	t.Cleanup(server.Close)
+
+	client := &http.Client{Transport: RetryMiddleware(RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    time.Millisecond,
+	})(http.DefaultTransport)}
+
+	for _, tc := range []struct {
+		name string
+		ctx  context.Context
+		want int32
+	}{
+		{"retried", context.Background(), 3},
+		{"without retry", WithoutRetry(context.Background()), 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			calls.Store(0)
+			req, err := http.NewRequestWithContext(tc.ctx, http.MethodPut, server.URL, bytes.NewReader([]byte("{}")))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusBadGateway {
+				t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusBadGateway)
+			}
+			if got := calls.Load(); got != tc.want {
+				t.Errorf("server saw %d requests, want %d", got, tc.want)
+			}
+		})
+	}
+}
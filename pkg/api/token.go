@@ -0,0 +1,180 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/goravaa/apache-polaris-cli/pkg/config"
+)
+
+// TokenRenewalSkew is how long before expiry a token is proactively renewed.
+const TokenRenewalSkew = 60 * time.Second
+
+// TokenProvider hands out bearer tokens for the active profile and renews
+// them from the stored client credentials when they are about to expire.
+// Tokens given with --token or POLARIS_TOKEN are used as-is.
+type TokenProvider struct {
+	mu     sync.Mutex
+	auth   *AuthClient
+	creds  *config.Credentials
+	static string
+}
+
+func NewTokenProvider(cfg *config.Config) (*TokenProvider, error) {
+	auth, err := NewAuthClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return newTokenProvider(auth)
+}
+
+// NewStaticTokenProvider always returns token and never renews it.
+func NewStaticTokenProvider(token string) *TokenProvider {
+	return &TokenProvider{static: token}
+}
+
+func newTokenProvider(auth *AuthClient) (*TokenProvider, error) {
+	token, source, err := config.ResolveToken()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &TokenProvider{auth: auth}
+	if source != config.TokenSourceCredentials {
+		p.static = token
+		return p, nil
+	}
+
+	creds, err := config.LoadCredentials()
+	if err != nil {
+		return nil, err
+	}
+	p.creds = creds
+	return p, nil
+}
+
+// Token returns a token that is not about to expire, renewing it first if
+// needed.
+func (p *TokenProvider) Token(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.static != "" {
+		return p.static, nil
+	}
+	if p.creds.Active().ExpiresWithin(TokenRenewalSkew) {
+		if err := p.renewLocked(ctx); err != nil {
+			return "", err
+		}
+	}
+	return p.creds.Active().AccessToken, nil
+}
+
+// ExpiresAt returns when the current token expires, or the zero time if
+// that is unknown.
+func (p *TokenProvider) ExpiresAt() time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.static != "" {
+		if claims, err := DecodeJWT(p.static); err == nil && claims.ExpiresAt != nil {
+			return *claims.ExpiresAt
+		}
+		return time.Time{}
+	}
+	return p.creds.Active().ExpiresAt()
+}
+
+// Renew unconditionally acquires a new token, unless the token that was
+// rejected has already been replaced by a concurrent renewal.
+func (p *TokenProvider) Renew(ctx context.Context, rejected string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.static != "" {
+		return "", unauthorizedf("unauthorized: the token given via --token or %s was rejected", config.EnvToken)
+	}
+	if active := p.creds.Active(); active.AccessToken != rejected {
+		return active.AccessToken, nil
+	}
+	if err := p.renewLocked(ctx); err != nil {
+		return "", err
+	}
+	return p.creds.Active().AccessToken, nil
+}
+
+func (p *TokenProvider) renewLocked(ctx context.Context) error {
+	creds, err := p.auth.Renew(ctx, p.creds)
+	if err != nil {
+		// Only a rejected login calls for logging in again. Network and
+		// server failures keep their own type.
+		if errors.Is(err, ErrUnauthorized) {
+			return unauthorizedf("unauthorized: your session has expired and could not be renewed (%v). Please run 'polaris auth login' again", err)
+		}
+		return fmt.Errorf("failed to renew the access token: %w", err)
+	}
+
+	if err := config.SaveCredentials(creds); err != nil {
+		return fmt.Errorf("failed to save renewed credentials: %w", err)
+	}
+	p.creds = creds
+	return nil
+}
+
+// authTransport sets the bearer token on every request and, when the server
+// answers 401, renews the token and retries the request once.
+type authTransport struct {
+	tokens *TokenProvider
+	base   http.RoundTripper
+}
+
+func NewAuthTransport(tokens *TokenProvider, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &authTransport{tokens: tokens, base: base}
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.tokens.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(withBearer(req, token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+
+	token, err = t.tokens.Renew(req.Context(), token)
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	retry := withBearer(req, token)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to replay request body: %w", err)
+		}
+		retry.Body = body
+	}
+	resp.Body.Close()
+
+	return t.base.RoundTrip(retry)
+}
+
+func withBearer(req *http.Request, token string) *http.Request {
+	r := req.Clone(req.Context())
+	r.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	return r
+}
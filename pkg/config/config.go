@@ -1,31 +1,100 @@
 package config
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 )
 
 const (
 	ConfigDirName       = ".polaris-cli"
 	ConfigFileName      = "config.json"
 	CredentialsFileName = "credentials.json"
+	CacheDirName        = "cache"
+
+	DefaultProfileName = "default"
+	DefaultHost        = "http://localhost:8181"
 )
 
 type Config struct {
-	Host          string `json:"host"`
-	Realm         string `json:"realm"`
-	CatalogPrefix string `json:"catalog_prefix"`
+	Name            string `json:"-"`
+	Host            string `json:"host"`
+	Realm           string `json:"realm"`
+	CatalogPrefix   string `json:"catalog_prefix"`
+	Warehouse       string `json:"warehouse,omitempty"`
+	Timeout         string `json:"timeout,omitempty"`
+	RequestTimeout  string `json:"request_timeout,omitempty"`
+	MaxAttempts     int    `json:"max_attempts,omitempty"`
+	RetryDeadline   string `json:"retry_deadline,omitempty"`
+	CredentialsFile string `json:"credentials_file,omitempty"`
+	CredentialStore string `json:"credential_store,omitempty"`
+
+	// CredentialProcess is a shell command that prints the client ID and
+	// secret as JSON. When set, the secret is never written to disk.
+	CredentialProcess string `json:"credential_process,omitempty"`
+
+	OIDC *OIDCConfig `json:"oidc,omitempty"`
+
+	CACertFile         string `json:"ca_cert,omitempty"`
+	ClientCertFile     string `json:"client_cert,omitempty"`
+	ClientKeyFile      string `json:"client_key,omitempty"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty"`
+	Proxy              string `json:"proxy,omitempty"`
+
+	Sources map[string]string `json:"-"`
 }
 
-type Credentials struct {
-	AccessToken  string `json:"access_token"`
-	TokenType    string `json:"token_type"`
-	ExpiresIn    int    `json:"expires_in"`
-	Scope        string `json:"scope"`
+// OIDCConfig describes an external identity provider whose tokens the
+// Polaris server trusts.
+type OIDCConfig struct {
+	Issuer       string `json:"issuer"`
 	ClientID     string `json:"client_id"`
-	ClientSecret string `json:"client_secret"`
+	Audience     string `json:"audience,omitempty"`
+	Scopes       string `json:"scopes,omitempty"`
+	RedirectPort int    `json:"redirect_port,omitempty"`
+}
+
+type Credentials struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type"`
+	ExpiresIn    int       `json:"expires_in"`
+	IssuedAt     time.Time `json:"issued_at,omitempty"`
+	Scope        string    `json:"scope"`
+	ClientID     string    `json:"client_id"`
+	ClientSecret string    `json:"client_secret"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	AuthMethod   string    `json:"auth_method,omitempty"`
+
+	// Scoped holds a down-scoped token obtained with 'polaris auth
+	// assume-role'. While set it is used instead of AccessToken.
+	Scoped *Credentials `json:"scoped,omitempty"`
+}
+
+// Active returns the credentials requests should be made with: the assumed
+// role's token if there is one, otherwise the full-scope token.
+func (c *Credentials) Active() *Credentials {
+	if c.Scoped != nil && c.Scoped.AccessToken != "" {
+		return c.Scoped
+	}
+	return c
+}
+
+// ExpiresAt returns when the access token expires, or the zero time if the
+// expiry is unknown.
+func (c *Credentials) ExpiresAt() time.Time {
+	if c.IssuedAt.IsZero() || c.ExpiresIn <= 0 {
+		return time.Time{}
+	}
+	return c.IssuedAt.Add(time.Duration(c.ExpiresIn) * time.Second)
+}
+
+// ExpiresWithin reports whether the token is known to expire within d.
+func (c *Credentials) ExpiresWithin(d time.Duration) bool {
+	expiresAt := c.ExpiresAt()
+	return !expiresAt.IsZero() && time.Now().Add(d).After(expiresAt)
 }
 
 func getConfigDir() (string, error) {
@@ -49,107 +118,197 @@ func ensureConfigDir() (string, error) {
 	return configDir, nil
 }
 
+// CacheDir returns the directory for the profile's cached catalog metadata,
+// creating it if needed.
+func CacheDir(cfg *Config) (string, error) {
+	configDir, err := ensureConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := profileCacheDir(configDir, cfg.Name)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return dir, nil
+}
+
+// profileCacheDir returns the cache directory of the named profile. It fails
+// unless the directory lies strictly inside the cache directory, so that a
+// profile name can never point a cache write or removal anywhere else.
+func profileCacheDir(configDir, name string) (string, error) {
+	root := filepath.Join(configDir, CacheDirName)
+	dir := filepath.Clean(filepath.Join(root, name))
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("profile %q has no valid cache directory: %s is not inside %s", name, dir, root)
+	}
+	return dir, nil
+}
+
 func LoadConfig() (*Config, error) {
-	configDir, err := getConfigDir()
+	profile, exists, err := loadProfile()
 	if err != nil {
 		return nil, err
 	}
+	return resolve(profile, exists)
+}
+
+// LoadProfile returns the active profile exactly as stored in config.json,
+// without flag or environment overrides applied.
+func LoadProfile() (*Config, error) {
+	profile, _, err := loadProfile()
+	return profile, err
+}
 
-	configPath := filepath.Join(configDir, ConfigFileName)
-	data, err := os.ReadFile(configPath)
+func loadProfile() (*Config, bool, error) {
+	file, err := LoadFile()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return &Config{
-				Host: "http://localhost:8181",
-			}, nil
-		}
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		return nil, false, err
 	}
 
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	name := ActiveProfile(file)
+	if err := validateProfileName(name); err != nil {
+		return nil, false, err
+	}
+	cfg, ok := file.Profiles[name]
+	if !ok {
+		if name == DefaultProfileName && len(file.Profiles) == 0 {
+			return &Config{Name: DefaultProfileName}, false, nil
+		}
+		return nil, false, fmt.Errorf("profile %q does not exist. Run 'polaris config set --profile %s --host <url>' to create it", name, name)
 	}
 
-	return &config, nil
+	return cfg, true, nil
 }
 
 func SaveConfig(config *Config) error {
-	configDir, err := ensureConfigDir()
+	file, err := LoadFile()
 	if err != nil {
 		return err
 	}
 
-	data, err := json.MarshalIndent(config, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
+	if config.Name == "" {
+		config.Name = ActiveProfile(file)
 	}
-
-	configPath := filepath.Join(configDir, ConfigFileName)
-	if err := os.WriteFile(configPath, data, 0600); err != nil {
-		return fmt.Errorf("failed to write config file: %w", err)
+	if err := validateProfileName(config.Name); err != nil {
+		return err
+	}
+	file.Profiles[config.Name] = config
+	if file.CurrentProfile == "" {
+		file.CurrentProfile = config.Name
 	}
 
-	return nil
+	return SaveFile(file)
 }
 
-func LoadCredentials() (*Credentials, error) {
+func credentialsPathFor(cfg *Config) (string, error) {
 	configDir, err := getConfigDir()
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	credentialsPath := filepath.Join(configDir, CredentialsFileName)
-	data, err := os.ReadFile(credentialsPath)
+	switch {
+	case cfg.CredentialsFile != "" && filepath.IsAbs(cfg.CredentialsFile):
+		return cfg.CredentialsFile, nil
+	case cfg.CredentialsFile != "":
+		return filepath.Join(configDir, cfg.CredentialsFile), nil
+	default:
+		return filepath.Join(configDir, defaultCredentialsFileName(cfg.Name)), nil
+	}
+}
+
+func defaultCredentialsFileName(profile string) string {
+	if profile == DefaultProfileName || profile == "" {
+		return CredentialsFileName
+	}
+	return fmt.Sprintf("credentials-%s.json", profile)
+}
+
+func activeStore() (*Config, CredentialStore, error) {
+	cfg, err := LoadProfile()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("not authenticated. Please run 'polaris auth login' first")
-		}
-		return nil, fmt.Errorf("failed to read credentials file: %w", err)
+		return nil, nil, err
 	}
 
-	var credentials Credentials
-	if err := json.Unmarshal(data, &credentials); err != nil {
-		return nil, fmt.Errorf("failed to parse credentials file: %w", err)
+	store, err := storeFor(cfg)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return &credentials, nil
+	return cfg, store, nil
 }
 
-func SaveCredentials(credentials *Credentials) error {
-	configDir, err := ensureConfigDir()
+// credentialStore returns the active profile and the store its credentials
+// are loaded from, saved to and cleared from, after any migration of a
+// legacy plaintext file.
+func credentialStore() (*Config, CredentialStore, error) {
+	cfg, store, err := activeStore()
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
-	data, err := json.MarshalIndent(credentials, "", "  ")
+	store, err = migratePlaintext(cfg, store)
 	if err != nil {
-		return fmt.Errorf("failed to marshal credentials: %w", err)
+		return nil, nil, err
 	}
 
-	credentialsPath := filepath.Join(configDir, CredentialsFileName)
-	if err := os.WriteFile(credentialsPath, data, 0600); err != nil {
-		return fmt.Errorf("failed to write credentials file: %w", err)
+	return cfg, store, nil
+}
+
+func LoadCredentials() (*Credentials, error) {
+	_, store, err := credentialStore()
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return store.Load()
+}
+
+func SaveCredentials(credentials *Credentials) error {
+	if _, err := ensureConfigDir(); err != nil {
+		return err
+	}
+
+	_, store, err := credentialStore()
+	if err != nil {
+		return err
+	}
+
+	return store.Save(credentials)
 }
 
 func ClearCredentials() error {
-	configDir, err := getConfigDir()
+	cfg, store, err := credentialStore()
 	if err != nil {
 		return err
 	}
 
-	credentialsPath := filepath.Join(configDir, CredentialsFileName)
-	if err := os.Remove(credentialsPath); err != nil {
-		if os.IsNotExist(err) {
-			return nil
+	return clearCredentials(cfg, store)
+}
+
+func clearCredentials(cfg *Config, store CredentialStore) error {
+	if err := store.Clear(); err != nil {
+		// An unreachable keyring is reported, but does not keep the
+		// file-backed copies from being removed.
+		if !errors.Is(err, ErrKeyringUnavailable) {
+			return err
 		}
-		return fmt.Errorf("failed to remove credentials file: %w", err)
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
 	}
 
-	return nil
+	// Also remove file-backed copies left behind by a previous store choice.
+	path, err := credentialsPathFor(cfg)
+	if err != nil {
+		return err
+	}
+	if err := (&encryptedFileStore{path: path + EncryptedFileSuffix}).Clear(); err != nil {
+		return err
+	}
+	return (&plaintextStore{path: path}).Clear()
 }
 
 func IsAuthenticated() bool {
@@ -161,12 +320,6 @@ func IsAuthenticated() bool {
 }
 
 func GetAccessToken() (string, error) {
-	creds, err := LoadCredentials()
-	if err != nil {
-		return "", err
-	}
-	if creds.AccessToken == "" {
-		return "", fmt.Errorf("no access token found. Please run 'polaris auth login' first")
-	}
-	return creds.AccessToken, nil
+	token, _, err := ResolveToken()
+	return token, err
 }
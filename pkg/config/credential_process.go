@@ -0,0 +1,48 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// ProcessCredentials is the JSON document a credential_process command
+// prints on stdout.
+type ProcessCredentials struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+}
+
+// RunCredentialProcess runs the profile's credential_process command through
+// the shell and returns the client credentials it prints. The command's
+// stderr is passed through so it can prompt or report errors.
+func RunCredentialProcess(command string) (*ProcessCredentials, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("/bin/sh", "-c", command)
+	}
+
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	cmd.Stdin = os.Stdin
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("credential_process %q failed: %w", command, err)
+	}
+
+	var creds ProcessCredentials
+	if err := json.Unmarshal(stdout.Bytes(), &creds); err != nil {
+		return nil, fmt.Errorf("credential_process %q did not print valid JSON: %w", command, err)
+	}
+	if creds.ClientID == "" || creds.ClientSecret == "" {
+		return nil, fmt.Errorf("credential_process %q must print both client_id and client_secret", command)
+	}
+
+	return &creds, nil
+}
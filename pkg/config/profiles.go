@@ -0,0 +1,209 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// File is the on-disk layout of config.json: a set of named connection
+// profiles and the one currently in use.
+type File struct {
+	CurrentProfile string             `json:"current_profile"`
+	Profiles       map[string]*Config `json:"profiles"`
+}
+
+var profileOverride string
+
+// ErrInvalidProfileName is returned for a profile name that cannot be used,
+// since names become part of file paths and keyring entries.
+var ErrInvalidProfileName = errors.New("invalid profile name")
+
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// validateProfileName accepts letters, digits, '_', '.' and '-', starting
+// with a letter or digit, and rejects "..", so that a name can never point
+// outside the config directory.
+func validateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) || strings.Contains(name, "..") {
+		return fmt.Errorf("%w %q: use letters, digits, '_', '.' and '-', starting with a letter or digit", ErrInvalidProfileName, name)
+	}
+	return nil
+}
+
+// SetProfileOverride selects a profile for the current process only,
+// without changing the current profile stored in config.json.
+func SetProfileOverride(name string) {
+	profileOverride = name
+}
+
+func ActiveProfile(file *File) string {
+	if profileOverride != "" {
+		return profileOverride
+	}
+	if name := os.Getenv(EnvProfile); name != "" {
+		return name
+	}
+	if file != nil && file.CurrentProfile != "" {
+		return file.CurrentProfile
+	}
+	return DefaultProfileName
+}
+
+func LoadFile() (*File, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	configPath := filepath.Join(configDir, ConfigFileName)
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &File{Profiles: map[string]*Config{}}, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var file File
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	if file.Profiles == nil {
+		// Files written before profiles existed hold a single flat config;
+		// treat it as the default profile.
+		var legacy Config
+		if err := json.Unmarshal(data, &legacy); err != nil {
+			return nil, fmt.Errorf("failed to parse config file: %w", err)
+		}
+		file.Profiles = map[string]*Config{}
+		if legacy.Host != "" || legacy.Realm != "" || legacy.CatalogPrefix != "" {
+			file.Profiles[DefaultProfileName] = &legacy
+			file.CurrentProfile = DefaultProfileName
+		}
+	}
+
+	for name, cfg := range file.Profiles {
+		if err := validateProfileName(name); err != nil {
+			return nil, fmt.Errorf("%s: %w; rename or remove the profile in the file", configPath, err)
+		}
+		if cfg == nil {
+			cfg = &Config{}
+			file.Profiles[name] = cfg
+		}
+		cfg.Name = name
+	}
+	if file.CurrentProfile != "" {
+		if err := validateProfileName(file.CurrentProfile); err != nil {
+			return nil, fmt.Errorf("%s: current_profile: %w", configPath, err)
+		}
+	}
+
+	return &file, nil
+}
+
+func SaveFile(file *File) error {
+	configDir, err := ensureConfigDir()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	configPath := filepath.Join(configDir, ConfigFileName)
+	if err := os.WriteFile(configPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	return nil
+}
+
+func (f *File) ProfileNames() []string {
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func UseProfile(name string) error {
+	if err := validateProfileName(name); err != nil {
+		return err
+	}
+
+	file, err := LoadFile()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := file.Profiles[name]; !ok {
+		return fmt.Errorf("profile %q does not exist", name)
+	}
+
+	file.CurrentProfile = name
+	return SaveFile(file)
+}
+
+// DeleteProfile removes a profile with its credentials and cache. If it was
+// the current profile, the default profile (or else the first remaining one
+// by name) becomes current, and its name is returned.
+func DeleteProfile(name string) (string, error) {
+	if err := validateProfileName(name); err != nil {
+		return "", err
+	}
+
+	file, err := LoadFile()
+	if err != nil {
+		return "", err
+	}
+
+	cfg, ok := file.Profiles[name]
+	if !ok {
+		return "", fmt.Errorf("profile %q does not exist", name)
+	}
+
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	cacheDir, err := profileCacheDir(configDir, name)
+	if err != nil {
+		return "", err
+	}
+
+	store, err := storeFor(cfg)
+	if err != nil {
+		return "", err
+	}
+	if err := clearCredentials(cfg, store); err != nil {
+		return "", err
+	}
+
+	if err := os.RemoveAll(cacheDir); err != nil {
+		return "", fmt.Errorf("failed to remove the cache of profile %q: %w", name, err)
+	}
+
+	delete(file.Profiles, name)
+	switched := ""
+	if file.CurrentProfile == name {
+		file.CurrentProfile = ""
+		if _, ok := file.Profiles[DefaultProfileName]; ok {
+			switched = DefaultProfileName
+		} else if names := file.ProfileNames(); len(names) > 0 {
+			switched = names[0]
+		}
+		file.CurrentProfile = switched
+	}
+
+	return switched, SaveFile(file)
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateProfileName(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"default", true},
+		{"prod-eu", true},
+		{"team_a.v2", true},
+		{"0", true},
+		{"", false},
+		{"..", false},
+		{"../..", false},
+		{"a/b", false},
+		{`a\b`, false},
+		{"a..b", false},
+		{".hidden", false},
+		{"-flag", false},
+		{"with space", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateProfileName(tt.name)
+			if tt.valid && err != nil {
+				t.Errorf("validateProfileName(%q) = %v, want nil", tt.name, err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidProfileName) {
+				t.Errorf("validateProfileName(%q) = %v, want ErrInvalidProfileName", tt.name, err)
+			}
+		})
+	}
+}
+
+func TestInvalidProfileNamesAreRejected(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(EnvProfile, "")
+	t.Cleanup(func() { SetProfileOverride("") })
+
+	for _, name := range []string{"../..", "a/b"} {
+		t.Run(name, func(t *testing.T) {
+			if err := SaveConfig(&Config{Name: name, Host: "http://x"}); !errors.Is(err, ErrInvalidProfileName) {
+				t.Errorf("SaveConfig = %v, want ErrInvalidProfileName", err)
+			}
+			if err := UseProfile(name); !errors.Is(err, ErrInvalidProfileName) {
+				t.Errorf("UseProfile = %v, want ErrInvalidProfileName", err)
+			}
+			if _, err := DeleteProfile(name); !errors.Is(err, ErrInvalidProfileName) {
+				t.Errorf("DeleteProfile = %v, want ErrInvalidProfileName", err)
+			}
+
+			SetProfileOverride(name)
+			if _, err := LoadConfig(); !errors.Is(err, ErrInvalidProfileName) {
+				t.Errorf("LoadConfig with --profile %s = %v, want ErrInvalidProfileName", name, err)
+			}
+			SetProfileOverride("")
+
+			t.Setenv(EnvProfile, name)
+			if _, err := LoadConfig(); !errors.Is(err, ErrInvalidProfileName) {
+				t.Errorf("LoadConfig with %s=%s = %v, want ErrInvalidProfileName", EnvProfile, name, err)
+			}
+			t.Setenv(EnvProfile, "")
+		})
+	}
+
+	// A name in config.json is checked as well.
+	dir := filepath.Join(home, ConfigDirName)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	data := []byte(`{"current_profile":"ok","profiles":{"ok":{"host":"http://x"},"../..":{"host":"http://y"}}}`)
+	if err := os.WriteFile(filepath.Join(dir, ConfigFileName), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFile(); !errors.Is(err, ErrInvalidProfileName) {
+		t.Errorf("LoadFile = %v, want ErrInvalidProfileName", err)
+	}
+}
+
+func TestDeleteCurrentProfile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(EnvProfile, "")
+	t.Setenv(EnvCredentialStore, StorePlaintext)
+
+	for _, name := range []string{"b", "c", "a"} {
+		if err := SaveConfig(&Config{Name: name, Host: "http://x"}); err != nil {
+			t.Fatalf("SaveConfig(%s): %v", name, err)
+		}
+	}
+	if err := UseProfile("c"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Deleting a profile that is not current leaves the current one alone.
+	if switched, err := DeleteProfile("b"); err != nil || switched != "" {
+		t.Fatalf("DeleteProfile(b) = %q, %v; want \"\", nil", switched, err)
+	}
+
+	switched, err := DeleteProfile("c")
+	if err != nil || switched != "a" {
+		t.Fatalf("DeleteProfile(c) = %q, %v; want \"a\", nil", switched, err)
+	}
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig after deleting the current profile: %v", err)
+	}
+	if cfg.Name != "a" {
+		t.Errorf("current profile = %q, want a", cfg.Name)
+	}
+
+	if switched, err := DeleteProfile("a"); err != nil || switched != "" {
+		t.Fatalf("DeleteProfile(a) = %q, %v; want \"\", nil", switched, err)
+	}
+	if _, err := LoadConfig(); err != nil {
+		t.Errorf("LoadConfig with no profiles left: %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	EnvProfile        = "POLARIS_PROFILE"
+	EnvHost           = "POLARIS_HOST"
+	EnvRealm          = "POLARIS_REALM"
+	EnvCatalogPrefix  = "POLARIS_CATALOG_PREFIX"
+	EnvWarehouse      = "POLARIS_WAREHOUSE"
+	EnvTimeout        = "POLARIS_TIMEOUT"
+	EnvRequestTimeout = "POLARIS_REQUEST_TIMEOUT"
+	EnvToken          = "POLARIS_TOKEN"
+	EnvMaxAttempts    = "POLARIS_MAX_ATTEMPTS"
+	EnvRetryDeadline  = "POLARIS_RETRY_DEADLINE"
+
+	DefaultRequestTimeout = 30 * time.Second
+	DefaultMaxAttempts    = 3
+	DefaultRetryDeadline  = 2 * time.Minute
+
+	TokenSourceCredentials = "stored credentials"
+)
+
+const (
+	SettingHost           = "host"
+	SettingRealm          = "realm"
+	SettingCatalogPrefix  = "catalog_prefix"
+	SettingWarehouse      = "warehouse"
+	SettingTimeout        = "timeout"
+	SettingRequestTimeout = "request_timeout"
+	SettingToken          = "token"
+	SettingMaxAttempts    = "max_attempts"
+	SettingRetryDeadline  = "retry_deadline"
+)
+
+// Overrides holds values given as global command-line flags. They take
+// precedence over environment variables and the profile file.
+type Overrides struct {
+	Host           string
+	Realm          string
+	CatalogPrefix  string
+	Warehouse      string
+	Timeout        string
+	RequestTimeout string
+	Token          string
+	MaxAttempts    string
+	RetryDeadline  string
+}
+
+var overrides Overrides
+
+func SetOverrides(o Overrides) {
+	overrides = o
+}
+
+// resolve layers flags and POLARIS_* environment variables over the values
+// stored in the profile and records where each setting came from.
+func resolve(profile *Config, profileExists bool) (*Config, error) {
+	cfg := *profile
+	cfg.Sources = map[string]string{}
+
+	profileSource := fmt.Sprintf("profile %q", cfg.Name)
+	layer := func(value *string, setting, def, env, flag, flagName string) {
+		switch {
+		case flag != "":
+			*value = flag
+			cfg.Sources[setting] = "flag " + flagName
+		case os.Getenv(env) != "":
+			*value = os.Getenv(env)
+			cfg.Sources[setting] = "env " + env
+		case *value != "" && profileExists:
+			cfg.Sources[setting] = profileSource
+		case def != "":
+			*value = def
+			cfg.Sources[setting] = "default"
+		default:
+			cfg.Sources[setting] = ""
+		}
+	}
+
+	layer(&cfg.Host, SettingHost, DefaultHost, EnvHost, overrides.Host, "--host")
+	layer(&cfg.Realm, SettingRealm, "", EnvRealm, overrides.Realm, "--realm")
+	layer(&cfg.CatalogPrefix, SettingCatalogPrefix, "", EnvCatalogPrefix, overrides.CatalogPrefix, "--prefix")
+	layer(&cfg.Warehouse, SettingWarehouse, "", EnvWarehouse, overrides.Warehouse, "--warehouse")
+	layer(&cfg.Timeout, SettingTimeout, "", EnvTimeout, overrides.Timeout, "--timeout")
+	layer(&cfg.RequestTimeout, SettingRequestTimeout, DefaultRequestTimeout.String(), EnvRequestTimeout, overrides.RequestTimeout, "--request-timeout")
+
+	if cfg.Timeout != "" {
+		if _, err := time.ParseDuration(cfg.Timeout); err != nil {
+			return nil, fmt.Errorf("invalid timeout %q (from %s): %w", cfg.Timeout, cfg.Sources[SettingTimeout], err)
+		}
+	}
+	if _, err := time.ParseDuration(cfg.RequestTimeout); err != nil {
+		return nil, fmt.Errorf("invalid request timeout %q (from %s): %w", cfg.RequestTimeout, cfg.Sources[SettingRequestTimeout], err)
+	}
+
+	attempts := ""
+	if cfg.MaxAttempts > 0 {
+		attempts = strconv.Itoa(cfg.MaxAttempts)
+	}
+	layer(&attempts, SettingMaxAttempts, strconv.Itoa(DefaultMaxAttempts), EnvMaxAttempts, overrides.MaxAttempts, "--max-attempts")
+	n, err := strconv.Atoi(attempts)
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("invalid max attempts %q (from %s): must be a positive integer", attempts, cfg.Sources[SettingMaxAttempts])
+	}
+	cfg.MaxAttempts = n
+
+	layer(&cfg.RetryDeadline, SettingRetryDeadline, DefaultRetryDeadline.String(), EnvRetryDeadline, overrides.RetryDeadline, "--retry-deadline")
+	if _, err := time.ParseDuration(cfg.RetryDeadline); err != nil {
+		return nil, fmt.Errorf("invalid retry deadline %q (from %s): %w", cfg.RetryDeadline, cfg.Sources[SettingRetryDeadline], err)
+	}
+
+	return &cfg, nil
+}
+
+// CommandTimeout returns the resolved limit for a whole command, or zero if
+// there is none.
+func (c *Config) CommandTimeout() time.Duration {
+	d, err := time.ParseDuration(c.Timeout)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// HTTPTimeout returns the resolved per-request timeout.
+func (c *Config) HTTPTimeout() time.Duration {
+	d, err := time.ParseDuration(c.RequestTimeout)
+	if err != nil || d <= 0 {
+		return DefaultRequestTimeout
+	}
+	return d
+}
+
+// RetryMaxAttempts returns how many times a request that fails with a
+// transient error is attempted in total.
+func (c *Config) RetryMaxAttempts() int {
+	if c.MaxAttempts < 1 {
+		return DefaultMaxAttempts
+	}
+	return c.MaxAttempts
+}
+
+// RetryDeadlineDuration returns the total time budget for one request
+// across all attempts. Zero disables the bound.
+func (c *Config) RetryDeadlineDuration() time.Duration {
+	if c.RetryDeadline == "" {
+		return DefaultRetryDeadline
+	}
+	d, err := time.ParseDuration(c.RetryDeadline)
+	if err != nil || d < 0 {
+		return DefaultRetryDeadline
+	}
+	return d
+}
+
+// ResolveToken returns the bearer token to use and where it came from:
+// the --token flag, POLARIS_TOKEN, or the profile's stored credentials.
+func ResolveToken() (string, string, error) {
+	if overrides.Token != "" {
+		return overrides.Token, "flag --token", nil
+	}
+	if token := os.Getenv(EnvToken); token != "" {
+		return token, "env " + EnvToken, nil
+	}
+
+	creds, err := LoadCredentials()
+	if err != nil {
+		return "", "", err
+	}
+	active := creds.Active()
+	if active.AccessToken == "" {
+		return "", "", fmt.Errorf("no access token found: %w", ErrNotAuthenticated)
+	}
+	return active.AccessToken, TokenSourceCredentials, nil
+}
@@ -0,0 +1,355 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	"github.com/zalando/go-keyring"
+)
+
+const (
+	StoreKeyring       = "keyring"
+	StoreEncryptedFile = "encrypted-file"
+	StorePlaintext     = "plaintext"
+
+	DefaultCredentialStore = StoreKeyring
+
+	KeyringService      = "polaris-cli"
+	EncryptedFileSuffix = ".age"
+
+	EnvCredentialStore      = "POLARIS_CREDENTIAL_STORE"
+	EnvCredentialPassphrase = "POLARIS_CREDENTIALS_PASSPHRASE"
+)
+
+// ErrNotAuthenticated is returned when the profile has no stored
+// credentials.
+var ErrNotAuthenticated = errors.New("not authenticated. Please run 'polaris auth login' first")
+
+// ErrKeyringUnavailable is returned when the OS keyring cannot be reached,
+// such as on a headless Linux machine without a Secret Service.
+var ErrKeyringUnavailable = errors.New("the OS keyring is unavailable")
+
+type keyringError struct {
+	action string
+	err    error
+}
+
+func (e *keyringError) Error() string {
+	return fmt.Sprintf("failed to %s the OS keyring (use --credential-store %s or %s if no keyring is available): %v", e.action, StoreEncryptedFile, StorePlaintext, e.err)
+}
+
+func (e *keyringError) Unwrap() error { return e.err }
+
+func (e *keyringError) Is(target error) bool { return target == ErrKeyringUnavailable }
+
+// CredentialStore persists the credentials of a single profile.
+type CredentialStore interface {
+	Name() string
+	Load() (*Credentials, error)
+	Save(credentials *Credentials) error
+	Clear() error
+}
+
+// PassphraseFunc supplies the passphrase for the encrypted-file store.
+// confirm is true when a new file is about to be written. The CLI replaces
+// it with an interactive prompt; the default only reads the environment.
+var PassphraseFunc = func(confirm bool) (string, error) {
+	if p := os.Getenv(EnvCredentialPassphrase); p != "" {
+		return p, nil
+	}
+	return "", fmt.Errorf("a passphrase is required for the encrypted credential store. Set %s", EnvCredentialPassphrase)
+}
+
+func ValidCredentialStore(name string) error {
+	switch name {
+	case StoreKeyring, StoreEncryptedFile, StorePlaintext:
+		return nil
+	default:
+		return fmt.Errorf("invalid credential store %q (expected %s, %s or %s)", name, StoreKeyring, StoreEncryptedFile, StorePlaintext)
+	}
+}
+
+func credentialStoreName(cfg *Config) string {
+	if name := os.Getenv(EnvCredentialStore); name != "" {
+		return name
+	}
+	if cfg.CredentialStore != "" {
+		return cfg.CredentialStore
+	}
+	return DefaultCredentialStore
+}
+
+func storeFor(cfg *Config) (CredentialStore, error) {
+	path, err := credentialsPathFor(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	name := credentialStoreName(cfg)
+	switch name {
+	case StoreKeyring:
+		return &keyringStore{user: cfg.Name}, nil
+	case StoreEncryptedFile:
+		return &encryptedFileStore{path: path + EncryptedFileSuffix}, nil
+	case StorePlaintext:
+		return &plaintextStore{path: path}, nil
+	default:
+		return nil, ValidCredentialStore(name)
+	}
+}
+
+// fallbackWarned keeps the warning about an unavailable keyring to one per
+// run, since credentials are loaded and saved more than once.
+var fallbackWarned bool
+
+// migratePlaintext moves a credentials file written by an older version of
+// the CLI into the configured store and removes the cleartext copy. It
+// returns the store to load, save and clear credentials with: if the keyring
+// is chosen but cannot be reached, the plaintext file is used instead, so
+// that logging in and existing logins keep working on machines without one.
+func migratePlaintext(cfg *Config, store CredentialStore) (CredentialStore, error) {
+	if store.Name() == StorePlaintext {
+		return store, nil
+	}
+
+	path, err := credentialsPathFor(cfg)
+	if err != nil {
+		return nil, err
+	}
+	legacy := &plaintextStore{path: path}
+
+	creds, err := legacy.Load()
+	if err != nil {
+		if !errors.Is(err, ErrNotAuthenticated) {
+			return nil, err
+		}
+		if ks, ok := store.(*keyringStore); ok {
+			if err := ks.reachable(); err != nil {
+				warnKeyringFallback(err, path)
+				return legacy, nil
+			}
+		}
+		return store, nil
+	}
+
+	if err := store.Save(creds); err != nil {
+		if errors.Is(err, ErrKeyringUnavailable) {
+			warnKeyringFallback(err, path)
+			return legacy, nil
+		}
+		return nil, fmt.Errorf("failed to migrate %s to the %s credential store: %w", path, store.Name(), err)
+	}
+	if err := legacy.Clear(); err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(os.Stderr, "Migrated plaintext credentials from %s to the %s credential store.\n", path, store.Name())
+	return store, nil
+}
+
+func warnKeyringFallback(err error, path string) {
+	if fallbackWarned {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Warning: %v\nKeeping plaintext credentials in %s.\n", err, path)
+	fallbackWarned = true
+}
+
+type plaintextStore struct {
+	path string
+}
+
+func (s *plaintextStore) Name() string { return StorePlaintext }
+
+func (s *plaintextStore) Load() (*Credentials, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotAuthenticated
+		}
+		return nil, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+
+	var credentials Credentials
+	if err := json.Unmarshal(data, &credentials); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials file: %w", err)
+	}
+
+	return &credentials, nil
+}
+
+func (s *plaintextStore) Save(credentials *Credentials) error {
+	data, err := json.MarshalIndent(credentials, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal credentials: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write credentials file: %w", err)
+	}
+
+	return nil
+}
+
+func (s *plaintextStore) Clear() error {
+	if err := os.Remove(s.path); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to remove credentials file: %w", err)
+	}
+	return nil
+}
+
+type keyringStore struct {
+	user string
+}
+
+func (s *keyringStore) Name() string { return StoreKeyring }
+
+func (s *keyringStore) Load() (*Credentials, error) {
+	secret, err := keyring.Get(KeyringService, s.user)
+	if err != nil {
+		if errors.Is(err, keyring.ErrNotFound) {
+			return nil, ErrNotAuthenticated
+		}
+		return nil, &keyringError{action: "read credentials from", err: err}
+	}
+
+	var credentials Credentials
+	if err := json.Unmarshal([]byte(secret), &credentials); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials from the OS keyring: %w", err)
+	}
+
+	return &credentials, nil
+}
+
+func (s *keyringStore) Save(credentials *Credentials) error {
+	data, err := json.Marshal(credentials)
+	if err != nil {
+		return fmt.Errorf("failed to marshal credentials: %w", err)
+	}
+
+	if err := keyring.Set(KeyringService, s.user, string(data)); err != nil {
+		if errors.Is(err, keyring.ErrSetDataTooBig) {
+			return fmt.Errorf("failed to write credentials to the OS keyring: %w", err)
+		}
+		return &keyringError{action: "write credentials to", err: err}
+	}
+
+	return nil
+}
+
+// reachable returns an ErrKeyringUnavailable error if the keyring cannot be
+// reached.
+func (s *keyringStore) reachable() error {
+	if _, err := keyring.Get(KeyringService, s.user); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return &keyringError{action: "reach", err: err}
+	}
+	return nil
+}
+
+func (s *keyringStore) Clear() error {
+	if err := keyring.Delete(KeyringService, s.user); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return &keyringError{action: "remove credentials from", err: err}
+	}
+	return nil
+}
+
+type encryptedFileStore struct {
+	path string
+}
+
+func (s *encryptedFileStore) Name() string { return StoreEncryptedFile }
+
+func (s *encryptedFileStore) Load() (*Credentials, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotAuthenticated
+		}
+		return nil, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+
+	passphrase, err := PassphraseFunc(false)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("invalid passphrase: %w", err)
+	}
+
+	r, err := age.Decrypt(bytes.NewReader(data), identity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt credentials file (wrong passphrase?): %w", err)
+	}
+
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt credentials file: %w", err)
+	}
+
+	var credentials Credentials
+	if err := json.Unmarshal(plaintext, &credentials); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials file: %w", err)
+	}
+
+	return &credentials, nil
+}
+
+func (s *encryptedFileStore) Save(credentials *Credentials) error {
+	data, err := json.Marshal(credentials)
+	if err != nil {
+		return fmt.Errorf("failed to marshal credentials: %w", err)
+	}
+
+	_, statErr := os.Stat(s.path)
+	passphrase, err := PassphraseFunc(os.IsNotExist(statErr))
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(passphrase) == "" {
+		return fmt.Errorf("passphrase must not be empty")
+	}
+
+	recipient, err := age.NewScryptRecipient(passphrase)
+	if err != nil {
+		return fmt.Errorf("invalid passphrase: %w", err)
+	}
+
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, recipient)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt credentials: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to encrypt credentials: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to encrypt credentials: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write credentials file: %w", err)
+	}
+
+	return nil
+}
+
+func (s *encryptedFileStore) Clear() error {
+	if err := os.Remove(s.path); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to remove credentials file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+func TestCredentialsWithoutKeyring(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(EnvProfile, "")
+	t.Setenv(EnvCredentialStore, "")
+	t.Cleanup(func() { fallbackWarned = false })
+
+	if err := SaveConfig(&Config{Name: DefaultProfileName, Host: "http://x"}); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	keyring.MockInitWithError(errors.New("no D-Bus session bus"))
+	want := &Credentials{AccessToken: "token-1", ClientID: "id", ClientSecret: "secret", Scope: "PRINCIPAL_ROLE:ALL"}
+	if err := SaveCredentials(want); err != nil {
+		t.Fatalf("SaveCredentials without a keyring: %v", err)
+	}
+	got, err := LoadCredentials()
+	if err != nil {
+		t.Fatalf("LoadCredentials without a keyring: %v", err)
+	}
+	if *got != *want {
+		t.Errorf("LoadCredentials = %+v, want %+v", got, want)
+	}
+	path := filepath.Join(home, ConfigDirName, CredentialsFileName)
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("plaintext fallback file: %v", err)
+	}
+
+	// Once a keyring is available, the file is moved into it.
+	keyring.MockInit()
+	got, err = LoadCredentials()
+	if err != nil {
+		t.Fatalf("LoadCredentials with a keyring: %v", err)
+	}
+	if *got != *want {
+		t.Errorf("LoadCredentials = %+v, want %+v", got, want)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("plaintext file still exists after migration: %v", err)
+	}
+	if _, err := (&keyringStore{user: DefaultProfileName}).Load(); err != nil {
+		t.Errorf("keyring after migration: %v", err)
+	}
+
+	if err := ClearCredentials(); err != nil {
+		t.Fatalf("ClearCredentials: %v", err)
+	}
+	if _, err := LoadCredentials(); !errors.Is(err, ErrNotAuthenticated) {
+		t.Errorf("LoadCredentials after ClearCredentials = %v, want ErrNotAuthenticated", err)
+	}
+}
@@ -0,0 +1,452 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format selects how a Result is written.
+type Format string
+
+const (
+	Table Format = "table"
+	Wide  Format = "wide"
+	JSON  Format = "json"
+	YAML  Format = "yaml"
+	CSV   Format = "csv"
+	Name  Format = "name"
+
+	// GoTemplate and JSONPath evaluate a template against the JSON form of
+	// the result, like kubectl's formats of the same names.
+	GoTemplate Format = "go-template"
+	JSONPath   Format = "jsonpath"
+)
+
+// Formats lists the supported formats in the order they are documented.
+var Formats = []Format{Table, Wide, JSON, YAML, CSV, Name, GoTemplate, JSONPath}
+
+// Spec is a parsed --output value: a format and, for go-template and
+// jsonpath, the template to evaluate.
+type Spec struct {
+	Format   Format
+	Template string
+
+	// Columns, SortBy and NoHeaders shape the table, wide and csv formats.
+	// Columns and SortBy name columns by their headers, ignoring case, and
+	// may use any column of the result, including wide ones.
+	Columns   []string
+	SortBy    string
+	NoHeaders bool
+}
+
+// ParseSpec parses an --output value such as "yaml" or
+// "jsonpath={.metadata.location}". A template format may be given without
+// a template, to be read from a file later; Validate reports it if it is
+// still missing.
+func ParseSpec(s string) (Spec, error) {
+	name, template, hasTemplate := strings.Cut(strings.TrimSpace(s), "=")
+	name = strings.ToLower(name)
+	for _, f := range Formats {
+		if string(f) != name {
+			continue
+		}
+		if hasTemplate && !f.templated() {
+			return Spec{}, fmt.Errorf("output format %q does not take a template", name)
+		}
+		spec := Spec{Format: f, Template: template}
+		if hasTemplate {
+			if err := spec.Validate(); err != nil {
+				return Spec{}, err
+			}
+		}
+		return spec, nil
+	}
+	return Spec{}, fmt.Errorf("invalid output format %q (expected table, wide, json, yaml, csv, name, go-template=... or jsonpath=...)", s)
+}
+
+func (f Format) templated() bool {
+	return f == GoTemplate || f == JSONPath
+}
+
+// Templated reports whether the format evaluates a template.
+func (s Spec) Templated() bool {
+	return s.Format.templated()
+}
+
+// Validate checks that a template format has a template that compiles.
+func (s Spec) Validate() error {
+	if !s.Templated() {
+		return nil
+	}
+	if s.Template == "" {
+		return fmt.Errorf("output format %s requires a template, as %s=... or with --template-file", s.Format, s.Format)
+	}
+	var err error
+	if s.Format == GoTemplate {
+		_, err = parseGoTemplate(s.Template)
+	} else {
+		_, err = parseJSONPath(s.Template)
+	}
+	return err
+}
+
+// String, Set and Type let a *Spec be used directly as a command-line flag
+// value, so that invalid formats are rejected while flags are parsed.
+func (s *Spec) String() string {
+	if s.Template == "" {
+		return string(s.Format)
+	}
+	return string(s.Format) + "=" + s.Template
+}
+
+func (s *Spec) Set(value string) error {
+	parsed, err := ParseSpec(value)
+	if err != nil {
+		return err
+	}
+	*s = parsed
+	return nil
+}
+
+func (s *Spec) Type() string { return "format" }
+
+// Result is everything a command has to show. Commands build a Result and
+// leave the choice of format to the user.
+type Result struct {
+	// Object is the raw API object, written as-is by the json and yaml
+	// formats.
+	Object any
+
+	// Headers and Rows are the tabular form, used by the table, wide and
+	// csv formats. Columns marked in Wide are only shown by the wide and
+	// csv formats, or when selected with --columns.
+	Headers []string
+	Rows    [][]string
+	Wide    []bool
+
+	// Items are the API objects behind the rows, one per row, used to
+	// report changes under --watch.
+	Items []any
+
+	// Text, when set, replaces the table in the table and wide formats
+	// unless columns are selected. It is used for descriptions and
+	// confirmations, which read better as prose.
+	Text func(w io.Writer) error
+
+	// Names are written one per line by the name format. They default to
+	// the first column.
+	Names []string
+
+	// Empty is written by the table format when there are no rows.
+	Empty string
+}
+
+// Column renders one field of a T as a table cell. Wide columns are only
+// shown by -o wide and csv, or when selected with --columns.
+type Column[T any] struct {
+	Header string
+	Value  func(T) string
+	Wide   bool
+}
+
+// Rows fills in the tabular form of r from items.
+func Rows[T any](r *Result, items []T, columns []Column[T]) {
+	r.Headers = make([]string, len(columns))
+	r.Wide = make([]bool, len(columns))
+	for i, c := range columns {
+		r.Headers[i] = c.Header
+		r.Wide[i] = c.Wide
+	}
+	r.Rows = make([][]string, 0, len(items))
+	r.Items = make([]any, 0, len(items))
+	for _, item := range items {
+		row := make([]string, len(columns))
+		for i, c := range columns {
+			row[i] = c.Value(item)
+		}
+		r.Rows = append(r.Rows, row)
+		r.Items = append(r.Items, item)
+	}
+}
+
+// Headers returns the headers of columns.
+func Headers[T any](columns []Column[T]) []string {
+	headers := make([]string, len(columns))
+	for i, c := range columns {
+		headers[i] = c.Header
+	}
+	return headers
+}
+
+// CheckColumns returns an ErrUnknownColumn error if Columns or SortBy name
+// a column that is not among headers, so that the mistake can be reported
+// before a command runs.
+func (s Spec) CheckColumns(headers []string) error {
+	for _, name := range s.Columns {
+		if _, err := columnIndex(headers, name); err != nil {
+			return err
+		}
+	}
+	if s.SortBy != "" {
+		if _, err := columnIndex(headers, s.SortBy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Print writes r to w in the given format.
+func Print(w io.Writer, spec Spec, r *Result) error {
+	switch spec.Format {
+	case GoTemplate:
+		return writeGoTemplate(w, spec.Template, r.Object)
+	case JSONPath:
+		return writeJSONPath(w, spec.Template, r.Object)
+	case JSON:
+		return writeJSON(w, r.Object)
+	case YAML:
+		return writeYAML(w, r.Object)
+	case CSV:
+		return writeCSV(w, spec, r)
+	case Name:
+		return writeNames(w, spec, r)
+	default:
+		return writeTable(w, spec, r)
+	}
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
+
+// writeYAML goes through JSON first, so that field names, omitempty and
+// custom marshalers of the API types are the same in both formats.
+func writeYAML(w io.Writer, v any) error {
+	generic, err := Generic(v)
+	if err != nil {
+		return err
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(generic); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// Generic converts v to the maps, slices and scalars of its JSON form.
+func Generic(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var generic any
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return numbers(generic), nil
+}
+
+// numbers turns json.Number values into int64 or float64 so that they are
+// written as numbers rather than strings.
+func numbers(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, e := range t {
+			t[k] = numbers(e)
+		}
+	case []any:
+		for i, e := range t {
+			t[i] = numbers(e)
+		}
+	case json.Number:
+		if n, err := t.Int64(); err == nil {
+			return n
+		}
+		if f, err := t.Float64(); err == nil {
+			return f
+		}
+		return t.String()
+	}
+	return v
+}
+
+func writeCSV(w io.Writer, spec Spec, r *Result) error {
+	headers, rows, _, err := tabulate(spec, r, true)
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if !spec.NoHeaders {
+		for i, h := range headers {
+			headers[i] = strings.ToLower(strings.ReplaceAll(h, " ", "_"))
+		}
+		if err := cw.Write(headers); err != nil {
+			return err
+		}
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+func writeNames(w io.Writer, spec Spec, r *Result) error {
+	_, rows, names, err := tabulate(spec, r, true)
+	if err != nil {
+		return err
+	}
+	if names == nil {
+		for _, row := range rows {
+			if len(row) > 0 {
+				names = append(names, row[0])
+			}
+		}
+	}
+	for _, name := range names {
+		if _, err := fmt.Fprintln(w, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeTable(w io.Writer, spec Spec, r *Result) error {
+	if r.Text != nil && len(spec.Columns) == 0 {
+		return r.Text(w)
+	}
+	headers, rows, _, err := tabulate(spec, r, spec.Format == Wide)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		if r.Empty != "" {
+			_, err := fmt.Fprintln(w, r.Empty)
+			return err
+		}
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if !spec.NoHeaders {
+		fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	}
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// ErrUnknownColumn is returned when --columns or --sort-by names a column
+// the result does not have.
+var ErrUnknownColumn = errors.New("unknown column")
+
+// tabulate sorts the rows of r and picks the columns to show: those named
+// in spec.Columns, or else every column that is not wide unless wide is
+// set. Names are reordered along with the rows.
+func tabulate(spec Spec, r *Result, wide bool) ([]string, [][]string, []string, error) {
+	var picked []int
+	if len(spec.Columns) > 0 {
+		for _, name := range spec.Columns {
+			i, err := columnIndex(r.Headers, name)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			picked = append(picked, i)
+		}
+	} else {
+		for i := range r.Headers {
+			if wide || i >= len(r.Wide) || !r.Wide[i] {
+				picked = append(picked, i)
+			}
+		}
+	}
+
+	order := make([]int, len(r.Rows))
+	for i := range order {
+		order[i] = i
+	}
+	if spec.SortBy != "" {
+		key, err := columnIndex(r.Headers, spec.SortBy)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		sort.SliceStable(order, func(a, b int) bool {
+			return lessCell(cell(r.Rows[order[a]], key), cell(r.Rows[order[b]], key))
+		})
+	}
+
+	headers := make([]string, len(picked))
+	for i, c := range picked {
+		headers[i] = r.Headers[c]
+	}
+	rows := make([][]string, len(order))
+	for i, o := range order {
+		row := make([]string, len(picked))
+		for j, c := range picked {
+			row[j] = cell(r.Rows[o], c)
+		}
+		rows[i] = row
+	}
+	var names []string
+	if r.Names != nil {
+		names = r.Names
+		if len(r.Names) == len(r.Rows) {
+			names = make([]string, len(order))
+			for i, o := range order {
+				names[i] = r.Names[o]
+			}
+		}
+	}
+	return headers, rows, names, nil
+}
+
+func columnIndex(headers []string, name string) (int, error) {
+	want := normalizeColumn(name)
+	for i, h := range headers {
+		if normalizeColumn(h) == want {
+			return i, nil
+		}
+	}
+	available := make([]string, len(headers))
+	for i, h := range headers {
+		available[i] = normalizeColumn(h)
+	}
+	return 0, fmt.Errorf("%w %q (available: %s)", ErrUnknownColumn, name, strings.Join(available, ", "))
+}
+
+func normalizeColumn(name string) string {
+	return strings.NewReplacer(" ", "-", "_", "-").Replace(strings.ToLower(strings.TrimSpace(name)))
+}
+
+func cell(row []string, i int) string {
+	if i < len(row) {
+		return row[i]
+	}
+	return ""
+}
+
+// lessCell compares numerically when both cells are numbers, and as text
+// otherwise. Timestamps are RFC 3339, so they sort correctly as text.
+func lessCell(a, b string) bool {
+	x, errA := strconv.ParseFloat(a, 64)
+	y, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		return x < y
+	}
+	return a < b
+}
@@ -0,0 +1,89 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Streams reports whether results in spec can be written a page at a time
+// by a Stream. The json, yaml and template formats write a single document,
+// and --sort-by needs every row, so those are printed once at the end.
+func (s Spec) Streams() bool {
+	switch s.Format {
+	case Table, Wide, CSV, Name:
+		return s.SortBy == ""
+	}
+	return false
+}
+
+// Stream writes the pages of a listing as they arrive. Headers are written
+// once, before the first rows, and the table columns are aligned per page.
+type Stream struct {
+	w       io.Writer
+	spec    Spec
+	started bool
+	rows    int
+	empty   string
+}
+
+func NewStream(w io.Writer, spec Spec) *Stream {
+	return &Stream{w: w, spec: spec}
+}
+
+// Write writes the rows of one page.
+func (s *Stream) Write(r *Result) error {
+	first := !s.started
+	s.started = true
+	s.empty = r.Empty
+	if s.spec.Format == Name {
+		s.rows += len(r.Rows)
+		return writeNames(s.w, s.spec, r)
+	}
+
+	headers, rows, _, err := tabulate(s.spec, r, s.spec.Format != Table)
+	if err != nil {
+		return err
+	}
+	s.rows += len(rows)
+
+	if s.spec.Format == CSV {
+		cw := csv.NewWriter(s.w)
+		if first && !s.spec.NoHeaders {
+			for i, h := range headers {
+				headers[i] = strings.ToLower(strings.ReplaceAll(h, " ", "_"))
+			}
+			if err := cw.Write(headers); err != nil {
+				return err
+			}
+		}
+		if err := cw.WriteAll(rows); err != nil {
+			return err
+		}
+		return cw.Error()
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+	tw := tabwriter.NewWriter(s.w, 0, 0, 2, ' ', 0)
+	if s.rows == len(rows) && !s.spec.NoHeaders {
+		fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	}
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// Close writes the result's Empty message in the table formats if no page
+// had any rows.
+func (s *Stream) Close() error {
+	if s.rows > 0 || s.empty == "" || (s.spec.Format != Table && s.spec.Format != Wide) {
+		return nil
+	}
+	_, err := fmt.Fprintln(s.w, s.empty)
+	return err
+}
@@ -0,0 +1,141 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// templateFuncs are available to go-template output in addition to the
+// text/template builtins.
+var templateFuncs = template.FuncMap{
+	"namespace": namespaceFunc,
+	"join":      joinFunc,
+	"timestamp": timestampFunc,
+	"json":      jsonFunc,
+}
+
+func parseGoTemplate(text string) (*template.Template, error) {
+	t, err := template.New("output").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid go-template: %w", err)
+	}
+	return t, nil
+}
+
+// parseJSONPath accepts kubectl's relaxed syntax, where the braces around a
+// single expression may be left out: ".metadata.location".
+func parseJSONPath(text string) (*jsonpath.JSONPath, error) {
+	if !strings.Contains(text, "{") {
+		text = "{" + text + "}"
+	}
+	j := jsonpath.New("output").AllowMissingKeys(true)
+	if err := j.Parse(text); err != nil {
+		return nil, fmt.Errorf("invalid jsonpath: %w", err)
+	}
+	return j, nil
+}
+
+func writeGoTemplate(w io.Writer, text string, v any) error {
+	t, err := parseGoTemplate(text)
+	if err != nil {
+		return err
+	}
+	generic, err := Generic(v)
+	if err != nil {
+		return err
+	}
+	if err := t.Execute(w, generic); err != nil {
+		return fmt.Errorf("failed to execute go-template: %w", err)
+	}
+	return nil
+}
+
+func writeJSONPath(w io.Writer, text string, v any) error {
+	j, err := parseJSONPath(text)
+	if err != nil {
+		return err
+	}
+	generic, err := Generic(v)
+	if err != nil {
+		return err
+	}
+	if err := j.Execute(w, generic); err != nil {
+		return fmt.Errorf("failed to execute jsonpath: %w", err)
+	}
+	return nil
+}
+
+// namespaceFunc joins the levels of an Iceberg namespace with dots:
+// {{namespace .namespace}} or {{namespace (index . "namespace") "/"}}.
+func namespaceFunc(levels any, sep ...string) (string, error) {
+	s := "."
+	if len(sep) > 0 {
+		s = sep[0]
+	}
+	return joinFunc(s, levels)
+}
+
+// joinFunc joins the elements of a list with sep: {{join ", " .items}}.
+func joinFunc(sep string, list any) (string, error) {
+	switch t := list.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return t, nil
+	case []string:
+		return strings.Join(t, sep), nil
+	case []any:
+		parts := make([]string, len(t))
+		for i, e := range t {
+			parts[i] = fmt.Sprint(e)
+		}
+		return strings.Join(parts, sep), nil
+	default:
+		return "", fmt.Errorf("join: expected a list, got %T", list)
+	}
+}
+
+// timestampFunc formats a time given in epoch milliseconds, as Iceberg
+// metadata stores it, or as an RFC 3339 string. The layout defaults to
+// RFC 3339 in UTC: {{timestamp (index .metadata "last-updated-ms")}} or
+// {{timestamp .createTimestamp "2006-01-02"}}.
+func timestampFunc(v any, layout ...string) (string, error) {
+	var t time.Time
+	switch x := v.(type) {
+	case nil:
+		return "", nil
+	case int64:
+		t = time.UnixMilli(x)
+	case int:
+		t = time.UnixMilli(int64(x))
+	case float64:
+		t = time.UnixMilli(int64(x))
+	case string:
+		parsed, err := time.Parse(time.RFC3339Nano, x)
+		if err != nil {
+			return "", fmt.Errorf("timestamp: %w", err)
+		}
+		t = parsed
+	default:
+		return "", fmt.Errorf("timestamp: unsupported value of type %T", v)
+	}
+	l := time.RFC3339
+	if len(layout) > 0 {
+		l = layout[0]
+	}
+	return t.UTC().Format(l), nil
+}
+
+func jsonFunc(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
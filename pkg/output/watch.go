@@ -0,0 +1,250 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Event types reported by a Watcher.
+const (
+	Added    = "ADDED"
+	Modified = "MODIFIED"
+	Deleted  = "DELETED"
+)
+
+var markers = map[string]string{Added: "+", Modified: "~", Deleted: "-"}
+
+// Event is a change to one item between two polls. In json output it is
+// written as one line: {"type": "MODIFIED", "object": {...}}.
+type Event struct {
+	Type   string `json:"type"`
+	Object any    `json:"object"`
+
+	row []string
+	key string
+}
+
+// WatchFormats are the formats a Watcher can write.
+var WatchFormats = []Format{Table, Wide, CSV, Name, JSON}
+
+// Watcher prints successive polls of the same result. The first result is
+// printed in full; after that only additions, removals and changes are
+// printed, each marked with +, - or ~. In json output every change,
+// including the initial items, is an Event on its own line.
+type Watcher struct {
+	w    io.Writer
+	spec Spec
+
+	started  bool
+	prev     *Result
+	prevText []string
+}
+
+func NewWatcher(w io.Writer, spec Spec) *Watcher {
+	return &Watcher{w: w, spec: spec}
+}
+
+// Update prints what changed since the previous result.
+func (wt *Watcher) Update(r *Result) error {
+	first := !wt.started
+	wt.started = true
+	prev := wt.prev
+	wt.prev = r
+
+	if wt.spec.Format != JSON && wt.spec.Format != CSV && r.Text != nil && len(wt.spec.Columns) == 0 {
+		return wt.updateText(r, first)
+	}
+
+	if first && wt.spec.Format != JSON && wt.spec.Format != CSV {
+		return Print(wt.w, wt.spec, r)
+	}
+	if prev == nil {
+		prev = &Result{}
+	}
+	events := Diff(prev, r)
+
+	switch wt.spec.Format {
+	case JSON:
+		enc := json.NewEncoder(wt.w)
+		enc.SetEscapeHTML(false)
+		for _, e := range events {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	case Name:
+		for _, e := range events {
+			if _, err := fmt.Fprintf(wt.w, "%s %s\n", markers[e.Type], e.key); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Rows of deleted items come from the previous result, so the columns
+	// are picked on a result that holds both.
+	combined := &Result{Headers: r.Headers, Wide: r.Wide}
+	if len(combined.Headers) == 0 {
+		combined.Headers, combined.Wide = prev.Headers, prev.Wide
+	}
+	for _, e := range events {
+		combined.Rows = append(combined.Rows, e.row)
+	}
+	headers, rows, _, err := tabulate(Spec{Columns: wt.spec.Columns}, combined, wt.spec.Format != Table)
+	if err != nil {
+		return err
+	}
+
+	if wt.spec.Format == CSV {
+		var buf bytes.Buffer
+		body := &Result{Headers: append([]string{"EVENT"}, headers...)}
+		for i, e := range events {
+			body.Rows = append(body.Rows, append([]string{e.Type}, rows[i]...))
+		}
+		spec := Spec{Format: CSV, NoHeaders: wt.spec.NoHeaders || !first}
+		if err := writeCSV(&buf, spec, body); err != nil {
+			return err
+		}
+		_, err := wt.w.Write(buf.Bytes())
+		return err
+	}
+
+	tw := tabwriter.NewWriter(wt.w, 0, 0, 2, ' ', 0)
+	for i, e := range events {
+		fmt.Fprintf(tw, "%s %s\n", markers[e.Type], strings.Join(rows[i], "\t"))
+	}
+	return tw.Flush()
+}
+
+// updateText diffs the rendered description line by line.
+func (wt *Watcher) updateText(r *Result, first bool) error {
+	var buf bytes.Buffer
+	if err := r.Text(&buf); err != nil {
+		return err
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if buf.Len() == 0 {
+		lines = nil
+	}
+	prev := wt.prevText
+	wt.prevText = lines
+	if first {
+		_, err := wt.w.Write(buf.Bytes())
+		return err
+	}
+	for _, d := range diffLines(prev, lines) {
+		if _, err := fmt.Fprintln(wt.w, d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Diff returns the items added to, removed from and changed in next
+// compared to prev. Items are matched by name, or by their first column.
+func Diff(prev, next *Result) []Event {
+	before := map[string]int{}
+	for i := range prev.Rows {
+		before[prev.key(i)] = i
+	}
+	seen := map[string]bool{}
+
+	var events []Event
+	for i := range next.Rows {
+		key := next.key(i)
+		seen[key] = true
+		e := Event{Object: next.item(i), row: next.Rows[i], key: key}
+		j, ok := before[key]
+		switch {
+		case !ok:
+			e.Type = Added
+		case !sameItem(prev, j, next, i):
+			e.Type = Modified
+		default:
+			continue
+		}
+		events = append(events, e)
+	}
+	var deleted []Event
+	for j := range prev.Rows {
+		if key := prev.key(j); !seen[key] {
+			deleted = append(deleted, Event{Type: Deleted, Object: prev.item(j), row: prev.Rows[j], key: key})
+		}
+	}
+	return append(deleted, events...)
+}
+
+func (r *Result) key(i int) string {
+	if len(r.Names) == len(r.Rows) {
+		return r.Names[i]
+	}
+	if len(r.Rows[i]) > 0 {
+		return r.Rows[i][0]
+	}
+	return fmt.Sprint(i)
+}
+
+// item returns the API object behind row i, falling back to the whole
+// object for single-row results and to the row itself otherwise.
+func (r *Result) item(i int) any {
+	if len(r.Items) == len(r.Rows) {
+		return r.Items[i]
+	}
+	if len(r.Rows) == 1 && r.Object != nil {
+		return r.Object
+	}
+	row := map[string]string{}
+	for j, h := range r.Headers {
+		row[normalizeColumn(h)] = cell(r.Rows[i], j)
+	}
+	return row
+}
+
+func sameItem(a *Result, i int, b *Result, j int) bool {
+	x, errX := json.Marshal(a.item(i))
+	y, errY := json.Marshal(b.item(j))
+	if errX != nil || errY != nil {
+		return strings.Join(a.Rows[i], "\x00") == strings.Join(b.Rows[j], "\x00")
+	}
+	return bytes.Equal(x, y)
+}
+
+// diffLines returns the lines removed from a and added in b, marked with
+// "- " and "+ ", in the order of a longest common subsequence walk.
+func diffLines(a, b []string) []string {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] >= lcs[i+1][j]):
+			out = append(out, "+ "+b[j])
+			j++
+		default:
+			out = append(out, "- "+a[i])
+			i++
+		}
+	}
+	return out
+}
@@ -0,0 +1,128 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ServiceName = "polaris-cli"
+
+	// EnvTraceParent carries a W3C traceparent from a calling process, such
+	// as a workflow task, so that the CLI's spans join its trace.
+	EnvTraceParent = "TRACEPARENT"
+	EnvTraceState  = "TRACESTATE"
+
+	envOTLPEndpoint       = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	envOTLPTracesEndpoint = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+)
+
+// Options selects where spans are exported. With neither set, spans are
+// only exported if the standard OTEL_EXPORTER_OTLP_*ENDPOINT variables are.
+type Options struct {
+	// Endpoint is an OTLP/HTTP endpoint URL, such as http://localhost:4318.
+	Endpoint string
+	// File receives one JSON document per span.
+	File string
+
+	Version string
+}
+
+// Setup installs the global tracer provider and W3C trace context
+// propagator. The returned function flushes pending spans, closes the trace
+// file and must be called before the process exits.
+func Setup(ctx context.Context, opts Options) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporters []sdktrace.SpanExporter
+	var file *os.File
+	if opts.File != "" {
+		file, err = os.OpenFile(opts.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open trace file: %w", err)
+		}
+		defer func() {
+			if err != nil {
+				file.Close()
+			}
+		}()
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create trace file exporter: %w", err)
+		}
+		exporters = append(exporters, exp)
+	}
+
+	endpoint := opts.Endpoint
+	if endpoint != "" || os.Getenv(envOTLPEndpoint) != "" || os.Getenv(envOTLPTracesEndpoint) != "" {
+		var options []otlptracehttp.Option
+		if endpoint != "" {
+			options = append(options, otlptracehttp.WithEndpointURL(endpoint))
+		}
+		exp, err := otlptracehttp.New(ctx, options...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+		}
+		exporters = append(exporters, exp)
+	}
+
+	if len(exporters) == 0 {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName(ServiceName),
+		semconv.ServiceVersion(opts.Version),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build trace resource: %w", err)
+	}
+
+	providerOpts := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+	for _, exp := range exporters {
+		providerOpts = append(providerOpts, sdktrace.WithBatcher(exp))
+	}
+	provider := sdktrace.NewTracerProvider(providerOpts...)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if file != nil {
+			if closeErr := file.Close(); closeErr != nil {
+				err = errors.Join(err, fmt.Errorf("failed to close trace file: %w", closeErr))
+			}
+		}
+		return err
+	}, nil
+}
+
+// ParentContext returns ctx carrying the span context given in TRACEPARENT
+// and TRACESTATE, if any.
+func ParentContext(ctx context.Context) context.Context {
+	carrier := propagation.MapCarrier{}
+	if v := os.Getenv(EnvTraceParent); v != "" {
+		carrier["traceparent"] = v
+	}
+	if v := os.Getenv(EnvTraceState); v != "" {
+		carrier["tracestate"] = v
+	}
+	if len(carrier) == 0 {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, carrier)
+}
+
+func Tracer() trace.Tracer {
+	return otel.Tracer("github.com/goravaa/apache-polaris-cli")
+}